@@ -0,0 +1,292 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"linkedin-automation/budget"  // Import budget to stop once the run budget is spent
+	"linkedin-automation/config"  // Import config for the live limits
+	"linkedin-automation/storage" // Import storage for the durable queue
+)
+
+var (
+	// ErrSkipped is returned (wrapped) by a handler when the action is no
+	// longer needed, e.g. because the profile was excluded meanwhile.
+	ErrSkipped = errors.New("action no longer needed")
+	// ErrLimitReached is returned (wrapped) by a handler when a limit stops
+	// actions of this kind for now. The action stays pending and no further
+	// actions of its kind are started in this run.
+	ErrLimitReached = errors.New("limit reached")
+	// ErrStop is returned (wrapped) by a handler to stop the worker. The
+	// action stays pending.
+	ErrStop = errors.New("stop processing actions")
+)
+
+// LaterError postpones an action to a later time instead of failing it.
+type LaterError struct {
+	At  time.Time
+	Err error
+}
+
+func (e *LaterError) Error() string {
+	return fmt.Sprintf("postponed until %s: %v", e.At.Format(time.RFC1123), e.Err)
+}
+
+func (e *LaterError) Unwrap() error {
+	return e.Err
+}
+
+// Later returns an error postponing the action to at because of err.
+func Later(at time.Time, err error) error {
+	return &LaterError{At: at, Err: err}
+}
+
+// Handler executes one action through the component responsible for it.
+type Handler func(action *storage.QueuedAction) error
+
+// Worker pulls due actions from the queue in storage, oldest scheduled
+// first, and executes them with the handler registered for their kind.
+type Worker struct {
+	Storage  *storage.Storage
+	Handlers map[storage.ActionKind]Handler
+	Limits   config.LimitsSource // Daily limits are checked before every action
+	// Wait is called before every action, e.g. to wait for working hours;
+	// nil does not wait. An error stops the worker.
+	Wait func() error
+	// Pace is called after every action, for a human-like delay before the
+	// next one, with the number of actions that can still run today; nil
+	// does not pause. An error stops the worker.
+	Pace func(kind storage.ActionKind, remaining int) error
+}
+
+// NewWorker creates a new Worker without handlers.
+func NewWorker(store *storage.Storage, limits config.LimitsSource) *Worker {
+	return &Worker{
+		Storage:  store,
+		Handlers: make(map[storage.ActionKind]Handler),
+		Limits:   limits,
+	}
+}
+
+// Handle registers the handler for a kind of action.
+func (w *Worker) Handle(kind storage.ActionKind, handler Handler) {
+	w.Handlers[kind] = handler
+}
+
+// Summary counts how the actions of a worker run ended.
+type Summary map[storage.ActionState]int
+
+// String formats the summary as e.g. "3 done, 1 failed".
+func (s Summary) String() string {
+	text := ""
+	for _, state := range []storage.ActionState{storage.ActionDone, storage.ActionFailed, storage.ActionSkipped, storage.ActionPending} {
+		if s[state] == 0 {
+			continue
+		}
+		if text != "" {
+			text += ", "
+		}
+		if state == storage.ActionPending {
+			text += fmt.Sprintf("%d postponed", s[state])
+		} else {
+			text += fmt.Sprintf("%d %s", s[state], state)
+		}
+	}
+	if text == "" {
+		return "nothing to do"
+	}
+	return text
+}
+
+// Run executes due actions until none are left, every remaining kind has
+// reached its limit, or a handler stops the worker. Kinds without a handler
+// are left in the queue.
+func (w *Worker) Run() (Summary, error) {
+	summary := make(Summary)
+	blocked := make(map[storage.ActionKind]bool)
+	for kind := range allKinds {
+		if w.Handlers[kind] == nil {
+			blocked[kind] = true
+		}
+	}
+
+	for {
+		if w.Wait != nil {
+			if err := w.Wait(); err != nil {
+				return summary, err
+			}
+		}
+		w.blockLimited(blocked)
+
+		action, err := w.Storage.ClaimDueAction(time.Now(), keys(blocked))
+		if err != nil {
+			return summary, err
+		}
+		if action == nil {
+			return summary, nil
+		}
+
+		log.Printf("Executing %s action %d for %s (attempt %d)", action.Kind, action.ID, action.ProfileURL, action.Attempts)
+		err = w.execute(action)
+		state, stop := w.finish(action, err)
+		summary[state]++
+		if stop {
+			return summary, nil
+		}
+		if errors.Is(err, ErrLimitReached) {
+			blocked[action.Kind] = true
+		}
+		if w.Pace != nil {
+			if err := w.Pace(action.Kind, w.remaining(blocked)); err != nil {
+				return summary, err
+			}
+		}
+	}
+}
+
+// execute runs the handler of an action. A panic, e.g. from a browser call
+// that lost its page, fails only this action instead of the whole run.
+func (w *Worker) execute(action *storage.QueuedAction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return w.Handlers[action.Kind](action)
+}
+
+// blockLimited blocks the kinds of action whose daily limit is reached.
+func (w *Worker) blockLimited(blocked map[storage.ActionKind]bool) {
+	for kind, reason := range w.reachedLimits() {
+		if !blocked[kind] {
+			log.Printf("Not starting more %s actions: %s", kind, reason)
+			blocked[kind] = true
+		}
+	}
+}
+
+// remaining counts the due actions that can still run today: those of kinds
+// not blocked, up to what is left of the daily limits.
+func (w *Worker) remaining(blocked map[storage.ActionKind]bool) int {
+	w.blockLimited(blocked)
+	due, err := w.Storage.CountDueActions(time.Now())
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return 0
+	}
+	left := w.leftToday()
+	total := 0
+	for kind, count := range due {
+		if blocked[kind] {
+			continue
+		}
+		if limit, ok := left[kind]; ok && limit < count {
+			count = limit
+		}
+		total += count
+	}
+	return total
+}
+
+// finish records the outcome of an action and returns its new state and
+// whether the worker should stop.
+func (w *Worker) finish(action *storage.QueuedAction, err error) (storage.ActionState, bool) {
+	var later *LaterError
+	var state storage.ActionState
+	var saveErr error
+	stop := false
+	switch {
+	case err == nil:
+		state = storage.ActionDone
+		saveErr = w.Storage.FinishAction(action.ID, state, "")
+	case errors.As(err, &later):
+		log.Printf("Postponing %s action %d for %s: %v", action.Kind, action.ID, action.ProfileURL, err)
+		state = storage.ActionPending
+		saveErr = w.Storage.RescheduleAction(action.ID, later.At, err.Error())
+	case errors.Is(err, ErrStop) || errors.Is(err, budget.ErrExhausted):
+		log.Printf("Stopping the action queue: %v", err)
+		state, stop = storage.ActionPending, true
+		saveErr = w.Storage.RescheduleAction(action.ID, action.ScheduledAt, err.Error())
+	case errors.Is(err, ErrLimitReached):
+		log.Printf("Not starting more %s actions: %v", action.Kind, err)
+		state = storage.ActionPending
+		saveErr = w.Storage.RescheduleAction(action.ID, action.ScheduledAt, err.Error())
+	case errors.Is(err, ErrSkipped):
+		log.Printf("Skipping %s action %d for %s: %v", action.Kind, action.ID, action.ProfileURL, err)
+		state = storage.ActionSkipped
+		saveErr = w.Storage.FinishAction(action.ID, state, err.Error())
+	default:
+		log.Printf("%s action %d for %s failed: %v", action.Kind, action.ID, action.ProfileURL, err)
+		state = storage.ActionFailed
+		saveErr = w.Storage.FinishAction(action.ID, state, err.Error())
+	}
+	if saveErr != nil {
+		log.Printf("Warning: %v", saveErr)
+	}
+	return state, stop
+}
+
+// reachedLimits returns the kinds of action whose daily limit is reached,
+// with the reason.
+func (w *Worker) reachedLimits() map[storage.ActionKind]string {
+	reached := make(map[storage.ActionKind]string)
+	if w.Limits == nil {
+		return reached
+	}
+	limits := w.Limits.Limits()
+	left := w.leftToday()
+	if count, ok := left[storage.ActionInvite]; ok && count <= 0 {
+		reached[storage.ActionInvite] = fmt.Sprintf("daily connection request limit (%d) reached", limits.DailyConnections)
+	}
+	if count, ok := left[storage.ActionMessage]; ok && count <= 0 {
+		reached[storage.ActionMessage] = fmt.Sprintf("daily message limit (%d) reached", limits.DailyMessages)
+	}
+	return reached
+}
+
+// leftToday returns how many more actions of the kinds with a daily limit
+// may run today.
+func (w *Worker) leftToday() map[storage.ActionKind]int {
+	left := make(map[storage.ActionKind]int)
+	if w.Limits == nil {
+		return left
+	}
+	limits := w.Limits.Limits()
+	if sent, err := w.Storage.GetCountOfSentRequestsToday(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else {
+		left[storage.ActionInvite] = limits.DailyConnections - sent
+	}
+	if sent, err := w.Storage.GetCountOfMessagesToday(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else {
+		left[storage.ActionMessage] = limits.DailyMessages - sent
+	}
+	return left
+}
+
+// allKinds are the kinds of action that can be queued.
+var allKinds = map[storage.ActionKind]bool{
+	storage.ActionInvite:   true,
+	storage.ActionMessage:  true,
+	storage.ActionWithdraw: true,
+	storage.ActionVisit:    true,
+}
+
+// IsKind reports whether kind can be queued.
+func IsKind(kind storage.ActionKind) bool {
+	return allKinds[kind]
+}
+
+// keys returns the kinds set in blocked.
+func keys(blocked map[storage.ActionKind]bool) []storage.ActionKind {
+	var kinds []storage.ActionKind
+	for kind, ok := range blocked {
+		if ok {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
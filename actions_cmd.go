@@ -0,0 +1,198 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation/actions"
+	"linkedin-automation/config"
+	"linkedin-automation/search"
+	"linkedin-automation/storage"
+)
+
+const actionsUsage = `usage:
+  linkedin-automation [flags] actions list [-state STATE]
+  linkedin-automation [flags] actions add -kind KIND [-at "2006-01-02 15:04"] [-template TEXT] [-var KEY=VALUE]... URL...
+  linkedin-automation [flags] actions cancel ID...
+
+Manages the queue of planned actions. KIND is invite, message, withdraw or
+visit; STATE is pending, running, done, failed, skipped or cancelled. Actions
+are executed by the next run once their time has come.`
+
+// runActionsCommand implements the "actions" subcommands, which manage the
+// queue of planned actions stored in the database, and returns the process
+// exit code.
+func runActionsCommand(loader *config.Loader, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, actionsUsage)
+		return 2
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	store, err := storage.NewStorage(cfg.Storage.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	switch args[0] {
+	case "list":
+		return listActions(store, args[1:])
+	case "add":
+		return addActions(store, cfg.Campaign.Name, args[1:])
+	case "cancel":
+		return cancelActions(store, args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, actionsUsage)
+		return 2
+	}
+}
+
+// listActions prints the queued actions, optionally only those in one state.
+func listActions(store *storage.Storage, args []string) int {
+	flags := flag.NewFlagSet("actions list", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, actionsUsage) }
+	state := flags.String("state", "", "only list actions in this state")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, actionsUsage)
+		return 2
+	}
+
+	queued, err := store.ListActions(storage.ActionState(*state))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(queued) == 0 {
+		fmt.Println("No actions.")
+		return 0
+	}
+	for _, action := range queued {
+		fmt.Printf("%d\t%s\t%s\t%s\t%s\tattempts: %d", action.ID, action.ScheduledAt.Local().Format("2006-01-02 15:04"),
+			action.State, action.Kind, action.ProfileURL, action.Attempts)
+		if action.LastError != "" {
+			fmt.Printf("\t%s", action.LastError)
+		}
+		fmt.Println()
+	}
+	return 0
+}
+
+// addActions plans an action of one kind for each profile URL given.
+func addActions(store *storage.Storage, campaign string, args []string) int {
+	flags := flag.NewFlagSet("actions add", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, actionsUsage) }
+	kind := flags.String("kind", "", "kind of action: invite, message, withdraw or visit")
+	at := flags.String("at", "", `local time to execute the actions at, e.g. "2026-10-19 09:30" (default: now)`)
+	flags.StringVar(&campaign, "campaign", campaign, "campaign the actions belong to")
+	template := flags.String("template", "", "note or message template replacing the configured one (invite and message)")
+	variables := payloadFlag{}
+	flags.Var(variables, "var", "template variable for the actions, e.g. -var Event=KubeCon (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 || !actions.IsKind(storage.ActionKind(*kind)) {
+		fmt.Fprintln(os.Stderr, actionsUsage)
+		return 2
+	}
+
+	var scheduledAt time.Time
+	if *at != "" {
+		var err error
+		if scheduledAt, err = time.ParseInLocation("2006-01-02 15:04", *at, time.Local); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid time %q: %v\n", *at, err)
+			return 2
+		}
+	}
+	if *template != "" {
+		variables[templatePayloadKey] = *template
+	}
+
+	status := 0
+	for _, rawURL := range flags.Args() {
+		profileURL, ok := search.NormalizeProfileURL(rawURL)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Not a profile URL: %s\n", rawURL)
+			status = 1
+			continue
+		}
+		action := &storage.QueuedAction{
+			Campaign:    campaign,
+			Kind:        storage.ActionKind(*kind),
+			ProfileURL:  profileURL,
+			Payload:     variables,
+			ScheduledAt: scheduledAt,
+		}
+		added, err := store.EnqueueAction(action)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !added {
+			fmt.Printf("A %s action is already planned for %s.\n", *kind, profileURL)
+			continue
+		}
+		fmt.Printf("Planned %s action %d for %s at %s.\n", *kind, action.ID, profileURL, action.ScheduledAt.Local().Format("2006-01-02 15:04"))
+	}
+	return status
+}
+
+// cancelActions cancels the pending actions with the given IDs.
+func cancelActions(store *storage.Storage, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, actionsUsage)
+		return 2
+	}
+	status := 0
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid action ID %q.\n", arg)
+			status = 2
+			continue
+		}
+		cancelled, err := store.CancelAction(id)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !cancelled {
+			fmt.Fprintf(os.Stderr, "No pending action %d.\n", id)
+			status = 1
+			continue
+		}
+		fmt.Printf("Cancelled action %d.\n", id)
+	}
+	return status
+}
+
+// payloadFlag collects -var KEY=VALUE flags into an action payload.
+type payloadFlag map[string]string
+
+func (p payloadFlag) String() string {
+	return ""
+}
+
+func (p payloadFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", value)
+	}
+	if strings.TrimSpace(key) == templatePayloadKey {
+		return fmt.Errorf("use -template to set the template")
+	}
+	p[strings.TrimSpace(key)] = val
+	return nil
+}
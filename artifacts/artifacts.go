@@ -0,0 +1,177 @@
+package artifacts
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"linkedin-automation/storage" // Import storage to record failed attempts
+)
+
+// captureTimeout bounds taking a screenshot or snapshot of a page that may
+// be in a broken state.
+const captureTimeout = 15 * time.Second
+
+// Recorder captures evidence of failed actions: a screenshot and a sanitized
+// HTML snapshot of the page, saved into a per-run directory, and a row in
+// failed_attempts pointing at them. A nil *Recorder does nothing, so
+// components can be used without one.
+type Recorder struct {
+	Dir     string           // Per-run directory; empty records failures without files
+	Storage *storage.Storage // Failed attempts are recorded here
+
+	mu  sync.Mutex
+	seq int // Numbers the captures of a run in order
+}
+
+// NewRecorder creates a Recorder saving into a directory named after the
+// run's start time under baseDir. An empty baseDir disables the files but
+// failed attempts are still recorded.
+func NewRecorder(baseDir string, startedAt time.Time, store *storage.Storage) *Recorder {
+	r := &Recorder{Storage: store}
+	if baseDir != "" {
+		r.Dir = filepath.Join(baseDir, startedAt.Format("20060102-150405"))
+	}
+	return r
+}
+
+// Capture records a failed try (attempt, starting at 1) of an action on
+// target (a profile or page URL) with the error that caused it, and saves the
+// current state of page as evidence. Failures to capture are logged, never
+// returned, so they cannot hide the original error.
+func (r *Recorder) Capture(page *rod.Page, action storage.ActionKind, target string, attempt int, cause error) *storage.FailedAttempt {
+	if r == nil {
+		return nil
+	}
+	failed := &storage.FailedAttempt{
+		Action:      action,
+		ProfileURL:  target,
+		Reason:      cause.Error(),
+		Attempt:     attempt,
+		AttemptedAt: time.Now(),
+	}
+
+	if r.Dir != "" && page != nil {
+		base, err := r.nextBase(action, target, attempt)
+		if err != nil {
+			log.Printf("Warning: Failed to create artifacts directory: %v", err)
+		} else {
+			page := page.Timeout(captureTimeout)
+			if failed.ScreenshotPath, err = saveScreenshot(page, base+".png"); err != nil {
+				log.Printf("Warning: Failed to save screenshot of %s: %v", target, err)
+			}
+			if failed.HTMLPath, err = saveSnapshot(page, base+".html"); err != nil {
+				log.Printf("Warning: Failed to save HTML snapshot of %s: %v", target, err)
+			}
+		}
+	}
+
+	if r.Storage != nil {
+		if err := r.Storage.SaveFailedAttempt(failed); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	log.Printf("Recorded failed %s on %s, attempt %d (screenshot: %s, HTML: %s)", action, target, attempt, orNone(failed.ScreenshotPath), orNone(failed.HTMLPath))
+	return failed
+}
+
+// nextBase creates the run directory if needed and returns the path, without
+// extension, for the next capture, e.g. "run-artifacts/20261018-150405/003-invite-jane-doe-1"
+// for the first try of an invitation.
+func (r *Recorder) nextBase(action storage.ActionKind, target string, attempt int) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return "", err
+	}
+	r.seq++
+	return filepath.Join(r.Dir, fmt.Sprintf("%03d-%s-%s-%d", r.seq, action, slug(target), attempt)), nil
+}
+
+// saveScreenshot saves a screenshot of the visible part of page to path.
+func saveScreenshot(page *rod.Page, path string) (string, error) {
+	screenshot, err := page.Screenshot(false, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, screenshot, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// snapshotJS serializes a copy of the page without scripts, styles, embedded
+// data and form values, which can hold session tokens or the password typed
+// into the login form.
+const snapshotJS = `() => {
+	const root = document.documentElement.cloneNode(true);
+	root.querySelectorAll('script, style, noscript, iframe, code, template, link[rel="stylesheet"], link[rel="preload"]')
+		.forEach(el => el.remove());
+	root.querySelectorAll('input, textarea, select').forEach(el => {
+		el.removeAttribute('value');
+		el.textContent = '';
+	});
+	root.querySelectorAll('meta').forEach(el => {
+		if (/csrf|token/i.test(el.getAttribute('name') || '')) el.remove();
+	});
+	return '<!DOCTYPE html>\n' + root.outerHTML;
+}`
+
+// saveSnapshot saves the sanitized HTML of page to path.
+func saveSnapshot(page *rod.Page, path string) (string, error) {
+	obj, err := page.Eval(snapshotJS)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(sanitize(obj.Value.Str())), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// tokenPattern matches CSRF tokens ("ajax:1234567890") and session-like
+// attribute values that survive in the markup.
+var tokenPattern = regexp.MustCompile(`ajax:\d+|(?i)(csrf[-_]?token|JSESSIONID|li_at)(["']?\s*[=:]\s*["']?)[^"'\s&;<>]+`)
+
+// sanitize redacts tokens left in the markup after snapshotJS.
+func sanitize(html string) string {
+	return tokenPattern.ReplaceAllStringFunc(html, func(match string) string {
+		if strings.HasPrefix(match, "ajax:") {
+			return "ajax:REDACTED"
+		}
+		groups := tokenPattern.FindStringSubmatch(match)
+		return groups[1] + groups[2] + "REDACTED"
+	})
+}
+
+// slugPattern matches characters not safe in file names.
+var slugPattern = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// slug returns a file-name-safe version of the last part of a URL, e.g.
+// "jane-doe" for https://www.linkedin.com/in/jane-doe/.
+func slug(target string) string {
+	target = strings.SplitN(target, "?", 2)[0]
+	parts := strings.Split(strings.Trim(target, "/"), "/")
+	s := slugPattern.ReplaceAllString(strings.ToLower(parts[len(parts)-1]), "-")
+	if s = strings.Trim(s, "-"); s == "" {
+		return "page"
+	}
+	if len(s) > 60 {
+		s = s[:60]
+	}
+	return s
+}
+
+// orNone returns path, or "none" if it is empty, for logging.
+func orNone(path string) string {
+	if path == "" {
+		return "none"
+	}
+	return path
+}
@@ -1,18 +1,20 @@
 package authentication
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
 	//"github.com/go-rod/rod/lib/proto" // Not used with JS cookie management
+	"linkedin-automation/artifacts" // Import artifacts to capture failed logins
 	"linkedin-automation/config" // Import the config package
 	"linkedin-automation/stealth" // Import the stealth package
+	"linkedin-automation/storage" // Import storage for the failed action kinds
 )
 
 // Cookie represents a single browser cookie.
@@ -39,6 +41,8 @@ type Authenticator struct {
 	Browser *rod.Browser
 	Page    *rod.Page
 	Config  *config.Config // Add a reference to the configuration
+	// Artifacts captures evidence of failed logins; nil disables it.
+	Artifacts *artifacts.Recorder
 }
 
 // NewAuthenticator creates a new Authenticator instance.
@@ -48,9 +52,22 @@ func NewAuthenticator(cfg *config.Config) *Authenticator {
 	}
 }
 
-// LaunchBrowser launches a new browser instance.
+// LaunchBrowser launches a new browser instance using the browser settings from the config.
 func (a *Authenticator) LaunchBrowser() error {
+	l := launcher.New().Headless(a.Config.Browser.Headless)
+	if a.Config.Browser.Bin != "" {
+		l = l.Bin(a.Config.Browser.Bin)
+	}
+	if a.Config.Browser.ProfileDir != "" {
+		l = l.UserDataDir(a.Config.Browser.ProfileDir)
+	}
+	controlURL, err := l.Launch()
+	if err != nil {
+		return fmt.Errorf("failed to launch browser: %w", err)
+	}
+
 	a.Browser = rod.New().
+		ControlURL(controlURL).
 		// .Timeout(10 * time.Minute) // Set a longer timeout for debugging
 		MustConnect()
 
@@ -68,13 +85,23 @@ func (a *Authenticator) CloseBrowser() {
 }
 
 // Login performs the login operation on LinkedIn.
-func (a *Authenticator) Login() error {
+func (a *Authenticator) Login() (err error) {
 	if a.Browser == nil {
 		return fmt.Errorf("browser not launched")
 	}
+	defer func() {
+		// Keep evidence of the page the login got stuck on
+		if err != nil && a.Page != nil {
+			pageURL := "https://www.linkedin.com/login"
+			if info, infoErr := a.Page.Info(); infoErr == nil {
+				pageURL = info.URL
+			}
+			a.Artifacts.Capture(a.Page, storage.ActionLogin, pageURL, 1, err)
+		}
+	}()
 
 	// Try loading cookies first
-	loadErr := a.LoadCookies("linkedin_cookies.json")
+	loadErr := a.LoadCookies(a.Config.Session.CookiePath)
 	if loadErr == nil {
 		log.Println("Loaded existing cookies, checking if session is valid...")
 		// Create a page and apply stealth
@@ -127,7 +154,7 @@ func (a *Authenticator) Login() error {
 	if currentURL == "https://www.linkedin.com/feed/" || currentURL == "https://www.linkedin.com/feed/?trk=nav_join" || (err == nil && feedModuleAfterLogin.MustVisible()) { // Corrected check
 		log.Println("Successfully logged in to LinkedIn!")
 		// Save cookies for future use
-		if err := a.SaveCookies("linkedin_cookies.json"); err != nil {
+		if err := a.SaveCookies(a.Config.Session.CookiePath); err != nil {
 			log.Printf("Warning: Failed to save cookies: %v", err)
 		}
 		return nil
@@ -174,23 +201,24 @@ func (a *Authenticator) SaveCookies(filename string) error {
 	}
 
 	// Execute JavaScript to get all cookies for the current domain
-	js := `
-		function getCookies() {
-			const cookies = document.cookie.split('; ').map(c => {
-				const [name, value] = c.split('=');
-				return { Name: name, Value: value };
-			});
-			return JSON.stringify(cookies);
-		}
-		getCookies();
-	`
-	res, err := a.Page.Evaluate(js).Str()
+	js := `() => {
+		const cookies = document.cookie.split('; ').map(c => {
+			const [name, value] = c.split('=');
+			return { Name: name, Value: value };
+		});
+		return JSON.stringify(cookies);
+	}`
+	obj, err := a.Page.Eval(js)
 	if err != nil {
 		return fmt.Errorf("failed to get cookies via JS: %w", err)
 	}
+	res := obj.Value.Str()
 
 	// Rod's Evaluate returns a string, so res is already the JSON string.
 	// We might need to unmarshal and re-marshal if we want pretty print, but for now, save as is.
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return fmt.Errorf("failed to create cookies directory: %w", err)
+	}
 	err = os.WriteFile(filename, []byte(res), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write cookies to file: %w", err)
@@ -237,8 +265,7 @@ func (a *Authenticator) LoadCookies(filename string) error {
 		}
 		// Note: SameSite, SameParty, etc. might need more complex JS to set or are not directly settable via document.cookie
 
-		js := fmt.Sprintf("document.cookie = `%s`;", cookieStr)
-		_, err := a.Page.Evaluate(js).Str()
+		_, err := a.Page.Eval(`c => { document.cookie = c }`, cookieStr)
 		if err != nil {
 			log.Printf("Warning: Failed to set cookie %s via JS: %v", cookie.Name, err)
 		}
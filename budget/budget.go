@@ -0,0 +1,119 @@
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrExhausted is returned once a run has spent its whole budget of an action.
+var ErrExhausted = errors.New("run budget exhausted")
+
+// Budget caps how many search result pages and profile visits a single run
+// may spend. A zero limit means unlimited, and a nil *Budget allows
+// everything, so components can be used without one.
+type Budget struct {
+	ResultPages   int // Result pages fetched from the site (cached pages are free)
+	ProfileVisits int // Profile page visits
+
+	mu            sync.Mutex
+	resultPages   int
+	cachedPages   int
+	profileVisits int
+}
+
+// New creates a Budget with the given limits.
+func New(resultPages, profileVisits int) *Budget {
+	return &Budget{ResultPages: resultPages, ProfileVisits: profileVisits}
+}
+
+// Reset starts spending the budget from zero again, e.g. on a new day of a
+// long-running process.
+func (b *Budget) Reset() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resultPages, b.cachedPages, b.profileVisits = 0, 0, 0
+}
+
+// SpendResultPage accounts for fetching a result page from the site, or
+// returns an error wrapping ErrExhausted if none are left.
+func (b *Budget) SpendResultPage() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ResultPages > 0 && b.resultPages >= b.ResultPages {
+		return fmt.Errorf("%w: %d of %d result pages used", ErrExhausted, b.resultPages, b.ResultPages)
+	}
+	b.resultPages++
+	return nil
+}
+
+// RecordCachedPage accounts for a result page served from the cache. Cached
+// pages do not count against the budget but are reported.
+func (b *Budget) RecordCachedPage() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cachedPages++
+}
+
+// SpendProfileVisit accounts for visiting a profile page, or returns an error
+// wrapping ErrExhausted if none are left.
+func (b *Budget) SpendProfileVisit() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ProfileVisits > 0 && b.profileVisits >= b.ProfileVisits {
+		return fmt.Errorf("%w: %d of %d profile visits used", ErrExhausted, b.profileVisits, b.ProfileVisits)
+	}
+	b.profileVisits++
+	return nil
+}
+
+// Usage is a snapshot of how much of a Budget was spent.
+type Usage struct {
+	ResultPages        int
+	ResultPagesLimit   int
+	CachedPages        int
+	ProfileVisits      int
+	ProfileVisitsLimit int
+}
+
+// Usage returns how much of the budget has been spent so far.
+func (b *Budget) Usage() Usage {
+	if b == nil {
+		return Usage{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Usage{
+		ResultPages:        b.resultPages,
+		ResultPagesLimit:   b.ResultPages,
+		CachedPages:        b.cachedPages,
+		ProfileVisits:      b.profileVisits,
+		ProfileVisitsLimit: b.ProfileVisits,
+	}
+}
+
+// String formats the usage as e.g. "result pages 3/10 (+2 cached), profile visits 40/unlimited".
+func (u Usage) String() string {
+	return fmt.Sprintf("result pages %d/%s (+%d cached), profile visits %d/%s",
+		u.ResultPages, limitString(u.ResultPagesLimit), u.CachedPages, u.ProfileVisits, limitString(u.ProfileVisitsLimit))
+}
+
+// limitString formats a limit, where zero means unlimited.
+func limitString(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(limit)
+}
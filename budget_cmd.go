@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"linkedin-automation/budget"
+	"linkedin-automation/config"
+	"linkedin-automation/storage"
+)
+
+const budgetUsage = `usage:
+  linkedin-automation [flags] budget [-days N]
+
+Reports how much of the per-run scrape budget each campaign used over the
+last N days (default 7).`
+
+// runBudgetCommand implements the "budget" command, which reports the scrape
+// budget consumed per campaign, and returns the process exit code.
+func runBudgetCommand(loader *config.Loader, args []string) int {
+	flags := flag.NewFlagSet("budget", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, budgetUsage) }
+	days := flags.Int("days", 7, "number of days to report")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 || *days < 1 {
+		fmt.Fprintln(os.Stderr, budgetUsage)
+		return 2
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	store, err := storage.NewStorage(cfg.Storage.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	runs, err := store.ListRunBudgets(time.Now().AddDate(0, 0, -*days))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(runs) == 0 {
+		fmt.Printf("No runs in the last %d days.\n", *days)
+		return 0
+	}
+
+	// Runs are ordered by campaign, so each campaign's runs are contiguous.
+	var campaign string
+	var total budget.Usage
+	count := 0
+	flush := func() {
+		if count > 0 {
+			fmt.Printf("%s\t%d run(s)\ttotal: result pages %d (+%d cached), profile visits %d\n",
+				campaign, count, total.ResultPages, total.CachedPages, total.ProfileVisits)
+		}
+	}
+	for _, run := range runs {
+		if run.Campaign != campaign {
+			flush()
+			campaign, total, count = run.Campaign, budget.Usage{}, 0
+		}
+		usage := budget.Usage{
+			ResultPages:        run.ResultPages,
+			ResultPagesLimit:   run.ResultPagesLimit,
+			CachedPages:        run.CachedPages,
+			ProfileVisits:      run.ProfileVisits,
+			ProfileVisitsLimit: run.ProfileVisitsLimit,
+		}
+		fmt.Printf("%s\t%s\t%s\n", run.Campaign, run.StartedAt.Format(time.RFC1123), usage)
+		total.ResultPages += usage.ResultPages
+		total.CachedPages += usage.CachedPages
+		total.ProfileVisits += usage.ProfileVisits
+		count++
+	}
+	flush()
+	return 0
+}
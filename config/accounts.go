@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// AccountsDir is where per-account files live when an account profile does
+// not set its own paths.
+const AccountsDir = "accounts"
+
+// AccountConfig is a named account profile. Selecting it with --account
+// replaces the linkedin credentials, session.cookie_path, browser.profile_dir
+// and storage.db_path of the base config. Paths left empty default to
+// accounts/<name>/..., so two accounts never share a session or database.
+type AccountConfig struct {
+	Username       string `mapstructure:"username"`
+	Password       string `mapstructure:"password"`
+	PasswordFile   string `mapstructure:"password_file"`
+	PasswordSource string `mapstructure:"password_source"`
+	CookiePath     string `mapstructure:"cookie_path"`
+	ProfileDir     string `mapstructure:"profile_dir"`
+	DBPath         string `mapstructure:"db_path"`
+}
+
+// withDefaultPaths fills empty paths with the per-account defaults.
+func (a AccountConfig) withDefaultPaths(name string) AccountConfig {
+	dir := filepath.Join(AccountsDir, name)
+	if a.CookiePath == "" {
+		a.CookiePath = filepath.Join(dir, "linkedin_cookies.json")
+	}
+	if a.ProfileDir == "" {
+		a.ProfileDir = filepath.Join(dir, "browser-profile")
+	}
+	if a.DBPath == "" {
+		a.DBPath = filepath.Join(dir, "linkedin_automation.db")
+	}
+	return a
+}
+
+// AccountNames returns the configured account names, sorted.
+func (c *Config) AccountNames() []string {
+	names := make([]string, 0, len(c.Accounts))
+	for name := range c.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyAccount merges the selected account profile over the settings already
+// in v, at config-file precedence so environment variables and flags still
+// win. It does nothing when no account is selected.
+func applyAccount(v *viper.Viper, name string) error {
+	if name == "" {
+		return nil
+	}
+	var accounts map[string]AccountConfig
+	if err := v.UnmarshalKey("accounts", &accounts); err != nil {
+		return fmt.Errorf("failed to read accounts: %w", err)
+	}
+	account, ok := accounts[name]
+	if !ok {
+		// Reported by accountProblems with the list of valid names.
+		return nil
+	}
+	account = account.withDefaultPaths(name)
+
+	return v.MergeConfigMap(map[string]interface{}{
+		"account": name,
+		"linkedin": map[string]interface{}{
+			"username":        account.Username,
+			"password":        account.Password,
+			"password_file":   account.PasswordFile,
+			"password_source": account.PasswordSource,
+		},
+		"session": map[string]interface{}{"cookie_path": account.CookiePath},
+		"browser": map[string]interface{}{"profile_dir": account.ProfileDir},
+		"storage": map[string]interface{}{"db_path": account.DBPath},
+	})
+}
+
+// accountProblems checks the account selection and that no two accounts
+// share a session file, browser profile or database.
+func (c *Config) accountProblems() []string {
+	var problems []string
+	names := c.AccountNames()
+
+	owners := make(map[string]string)
+	claim := func(kind, path, name string) {
+		key := kind + ":" + filepath.Clean(path)
+		if other, ok := owners[key]; ok {
+			problems = append(problems, fmt.Sprintf("accounts %q and %q share the same %s %s", other, name, kind, path))
+			return
+		}
+		owners[key] = name
+	}
+	for _, name := range names {
+		account := c.Accounts[name].withDefaultPaths(name)
+		claim("cookie_path", account.CookiePath, name)
+		claim("profile_dir", account.ProfileDir, name)
+		claim("db_path", account.DBPath, name)
+	}
+
+	switch _, ok := c.Accounts[c.Account]; {
+	case c.Account == "" && len(names) > 0:
+		problems = append(problems, fmt.Sprintf("accounts are configured; select one with --account (one of: %s)", strings.Join(names, ", ")))
+	case c.Account != "" && len(names) == 0:
+		problems = append(problems, fmt.Sprintf("account %q selected but no accounts are configured", c.Account))
+	case c.Account != "" && !ok:
+		problems = append(problems, fmt.Sprintf("unknown account %q (configured: %s)", c.Account, strings.Join(names, ", ")))
+	}
+	return problems
+}
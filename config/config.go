@@ -2,65 +2,536 @@ package config
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds the application's configuration settings.
 type Config struct {
-	LinkedIn struct {
-		Username string `mapstructure:"username"`
-		Password string `mapstructure:"password"`
-	} `mapstructure:"linkedin"`
-	// Add other configuration fields here as needed
-}
-
-// LoadConfig reads configuration from file and environment variables.
-func LoadConfig() (*Config, error) {
-	viper.SetConfigName("config") // name of config file (without extension)
-	viper.SetConfigType("yaml")   // or "json"
-	viper.AddConfigPath(".")      // path to look for the config file in the current directory
-	viper.AddConfigPath("./config") // path to look for the config file in the config directory
-
-	// Read environment variables
-	viper.SetEnvPrefix("LINKEDIN_AUTOMATION") // prefix for environment variables
-	viper.AutomaticEnv()                    // read in environment variables that match
-
-	// Set default values
-	viper.SetDefault("linkedin.username", "")
-	viper.SetDefault("linkedin.password", "")
-
-	var cfg Config
-
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found; ignore error if we're relying solely on environment variables
-			fmt.Println("Config file not found, relying on environment variables or defaults.")
-		} else {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+	// Account is the selected account profile, if any.
+	Account   string                   `mapstructure:"account"`
+	Accounts  map[string]AccountConfig `mapstructure:"accounts"`
+	LinkedIn  LinkedInConfig           `mapstructure:"linkedin"`
+	Storage   StorageConfig            `mapstructure:"storage"`
+	Session   SessionConfig            `mapstructure:"session"`
+	Limits    LimitsConfig             `mapstructure:"limits"`
+	Pacing    PacingConfig             `mapstructure:"pacing"`
+	Browser   BrowserConfig            `mapstructure:"browser"`
+	Search    SearchConfig             `mapstructure:"search"`
+	Campaign  CampaignConfig           `mapstructure:"campaign"`
+	Budget    BudgetConfig             `mapstructure:"budget"`
+	Retry     RetryConfig              `mapstructure:"retry"`
+	Templates TemplatesConfig          `mapstructure:"templates"`
+	Schedule  ScheduleConfig           `mapstructure:"schedule"`
+	Logging   LoggingConfig            `mapstructure:"logging"`
+}
+
+// LinkedInConfig holds the account credentials.
+type LinkedInConfig struct {
+	Username string `mapstructure:"username"`
+	// Password is resolved at load time and never written back to disk.
+	Password string `mapstructure:"password"`
+	// PasswordFile points at a file containing only the password.
+	PasswordFile string `mapstructure:"password_file"`
+	// PasswordSource is a "scheme:reference" secret reference handled by a
+	// registered SecretProvider (env, file, keyring, exec).
+	PasswordSource string `mapstructure:"password_source"`
+}
+
+// StorageConfig configures the SQLite database.
+type StorageConfig struct {
+	DBPath string `mapstructure:"db_path"`
+	// ArtifactsDir holds screenshots of failed actions.
+	ArtifactsDir string `mapstructure:"artifacts_dir"`
+}
+
+// SessionConfig configures where the login session is persisted.
+type SessionConfig struct {
+	CookiePath string `mapstructure:"cookie_path"`
+}
+
+// LimitsConfig caps how much outreach happens per day.
+type LimitsConfig struct {
+	DailyConnections int `mapstructure:"daily_connections"`
+	DailyMessages    int `mapstructure:"daily_messages"`
+	NoteMaxLength    int `mapstructure:"note_max_length"`
+	// NoteOverflow is "reject" or "truncate", for notes over NoteMaxLength.
+	NoteOverflow     string `mapstructure:"note_overflow"`
+	MessageMaxLength int    `mapstructure:"message_max_length"`
+	// MessageOverflow is "reject" or "truncate", for messages over MessageMaxLength.
+	MessageOverflow string `mapstructure:"message_overflow"`
+	// InvitationLimitBackoff is how long no invitations are sent after
+	// LinkedIn showed its weekly invitation limit; 0 disables the backoff.
+	InvitationLimitBackoff time.Duration `mapstructure:"invitation_limit_backoff"`
+}
+
+// DelayRange is an inclusive range a random delay is picked from.
+type DelayRange struct {
+	Min time.Duration `mapstructure:"min"`
+	Max time.Duration `mapstructure:"max"`
+}
+
+// PacingConfig controls the human-like delays between actions.
+type PacingConfig struct {
+	BetweenConnections DelayRange `mapstructure:"between_connections"`
+	BetweenMessages    DelayRange `mapstructure:"between_messages"`
+}
+
+// BrowserConfig controls how the browser is launched.
+type BrowserConfig struct {
+	Headless bool `mapstructure:"headless"`
+	// Bin is the Chrome/Chromium binary; empty lets Rod find or download one.
+	Bin string `mapstructure:"bin"`
+	// ProfileDir is the browser user data directory; empty uses a temporary one.
+	ProfileDir string `mapstructure:"profile_dir"`
+}
+
+// SearchConfig holds the default search criteria. Company, location,
+// industry and school entries may be names from the facet table or IDs.
+type SearchConfig struct {
+	JobTitle         string   `mapstructure:"job_title"`
+	Keywords         []string `mapstructure:"keywords"`
+	CurrentCompanies []string `mapstructure:"current_companies"`
+	PastCompanies    []string `mapstructure:"past_companies"`
+	Locations        []string `mapstructure:"locations"`
+	Network          []int    `mapstructure:"network"`
+	Industries       []string `mapstructure:"industries"`
+	Schools          []string `mapstructure:"schools"`
+	ProfileLanguages []string `mapstructure:"profile_languages"`
+	PageLimit        int      `mapstructure:"page_limit"`
+	// FacetTableFile extends the built-in name-to-ID table used to resolve
+	// facets and caches IDs looked up at runtime.
+	FacetTableFile string `mapstructure:"facet_table_file"`
+	// CacheTTL is how long scraped result pages are reused; 0 disables the cache.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+// CampaignConfig identifies the campaign profiles are queued for. A campaign
+// file passed with --campaign typically sets its own name.
+type CampaignConfig struct {
+	Name    string          `mapstructure:"name"`
+	Exclude ExclusionConfig `mapstructure:"exclude"`
+	Scoring ScoringConfig   `mapstructure:"scoring"`
+	// Variables fill template placeholders for this campaign, overriding
+	// templates.variables.
+	Variables map[string]string `mapstructure:"variables"`
+}
+
+// ExclusionConfig lists the rules that drop a queued profile before any
+// outreach. Text rules match case-insensitively.
+type ExclusionConfig struct {
+	// FirstDegree drops people who are already connections.
+	FirstDegree bool `mapstructure:"first_degree"`
+	// AlreadyContacted drops anyone who was already sent an invitation.
+	AlreadyContacted bool `mapstructure:"already_contacted"`
+	// Companies drops current employees, e.g. of our own company or clients.
+	Companies []string `mapstructure:"companies"`
+	// HeadlineKeywords drops headlines containing any of these words, e.g. "recruiter".
+	HeadlineKeywords []string `mapstructure:"headline_keywords"`
+	// Locations drops profiles whose location contains any of these.
+	Locations []string `mapstructure:"locations"`
+}
+
+// ScoringConfig weighs queued profiles so the best matches are contacted
+// first when there are more profiles than the daily budget. Keys of the
+// weight maps match case-insensitively; negative weights push profiles down.
+type ScoringConfig struct {
+	// TitleKeywords adds the weight of every keyword found in the headline.
+	TitleKeywords map[string]float64 `mapstructure:"title_keywords"`
+	// Seniority adds the weight of the highest seniority level found in the
+	// headline, e.g. {"senior": 2, "director": 5}.
+	Seniority map[string]float64 `mapstructure:"seniority"`
+	// Locations adds the highest weight among locations the profile is in.
+	Locations map[string]float64 `mapstructure:"locations"`
+	// Companies adds the highest weight among companies the profile works at.
+	Companies map[string]float64 `mapstructure:"companies"`
+	// MutualConnectionWeight is added per mutual connection, counting at most
+	// MaxMutualConnections of them.
+	MutualConnectionWeight float64 `mapstructure:"mutual_connection_weight"`
+	MaxMutualConnections   int     `mapstructure:"max_mutual_connections"`
+}
+
+// BudgetConfig caps how much scraping a single run may do. Zero means
+// unlimited.
+type BudgetConfig struct {
+	// ResultPages is the number of search result pages fetched from the site;
+	// pages served from the search cache are free.
+	ResultPages int `mapstructure:"result_pages"`
+	// ProfileVisits is the number of profile pages visited.
+	ProfileVisits int `mapstructure:"profile_visits"`
+}
+
+// RetryConfig controls how actions failing with transient browser errors
+// (timeouts, aborted navigations, stale elements) are retried.
+type RetryConfig struct {
+	// Attempts is the total number of tries, including the first.
+	Attempts int `mapstructure:"attempts"`
+	// InitialDelay is the wait before the first retry; it doubles for each
+	// further retry, up to MaxDelay, with random jitter.
+	InitialDelay time.Duration `mapstructure:"initial_delay"`
+	MaxDelay     time.Duration `mapstructure:"max_delay"`
+	// AttemptTimeout is how long one try may wait on the page before it
+	// fails with a timeout and is retried; 0 waits indefinitely.
+	AttemptTimeout time.Duration `mapstructure:"attempt_timeout"`
+}
+
+// TemplatesConfig holds the outreach message templates.
+type TemplatesConfig struct {
+	ConnectionNote string `mapstructure:"connection_note"`
+	FollowUp       string `mapstructure:"follow_up"`
+	// Variables fill {{Placeholders}} in the templates. Config keys are
+	// case-insensitive, so placeholders are matched case-insensitively too.
+	Variables map[string]string `mapstructure:"variables"`
+}
+
+// ScheduleConfig controls when outreach actions may run.
+type ScheduleConfig struct {
+	// Paused stops all outreach until it is set back to false.
+	Paused bool `mapstructure:"paused"`
+	// Timezone is the IANA name of the account's timezone, e.g.
+	// "Europe/Berlin", in which working hours and holidays are read. Empty
+	// uses the machine's local time.
+	Timezone     string       `mapstructure:"timezone"`
+	WorkingHours WorkingHours `mapstructure:"working_hours"`
+	// Days overrides the working hours for single days of the week.
+	Days DaysConfig `mapstructure:"days"`
+	// HolidaysFile is an iCal (.ics) file whose events are days off.
+	HolidaysFile string `mapstructure:"holidays_file"`
+	// Spread paces actions evenly over the rest of the working window
+	// instead of sending them back to back.
+	Spread bool `mapstructure:"spread"`
+}
+
+// WorkingHours is a daily "HH:MM" window in local time. Leaving both ends
+// empty allows actions at any time; End may be "24:00".
+type WorkingHours struct {
+	Start string `mapstructure:"start"`
+	End   string `mapstructure:"end"`
+}
+
+// DaysConfig holds the working hours of each day of the week as one or more
+// "HH:MM-HH:MM" windows separated by commas, e.g. "09:00-12:00, 13:00-17:30",
+// or "off" for a day without outreach. An empty day uses the daily working
+// hours.
+type DaysConfig struct {
+	Monday    string `mapstructure:"monday"`
+	Tuesday   string `mapstructure:"tuesday"`
+	Wednesday string `mapstructure:"wednesday"`
+	Thursday  string `mapstructure:"thursday"`
+	Friday    string `mapstructure:"friday"`
+	Saturday  string `mapstructure:"saturday"`
+	Sunday    string `mapstructure:"sunday"`
+}
+
+// Get returns the working hours configured for a day of the week.
+func (d DaysConfig) Get(day time.Weekday) string {
+	return [...]string{d.Sunday, d.Monday, d.Tuesday, d.Wednesday, d.Thursday, d.Friday, d.Saturday}[day]
+}
+
+// Window is a span of a day in minutes since midnight, End excluded.
+type Window struct {
+	Start int
+	End   int
+}
+
+// Windows returns the working windows of a day of the week, in order, or
+// none for a day off. A day without its own hours uses the daily working
+// hours, and without those it is worked around the clock.
+func (s ScheduleConfig) Windows(day time.Weekday) ([]Window, error) {
+	spec := strings.TrimSpace(s.Days.Get(day))
+	if spec == "" {
+		wh := s.WorkingHours
+		if wh.Start == "" && wh.End == "" {
+			return []Window{{Start: 0, End: 24 * 60}}, nil
 		}
+		spec = wh.Start + "-" + wh.End
 	}
+	return ParseWindows(spec)
+}
 
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+// ParseWindows parses "HH:MM-HH:MM" windows separated by commas, or "off"
+// for none. Windows must be in order and must not overlap.
+func ParseWindows(spec string) ([]Window, error) {
+	if strings.EqualFold(strings.TrimSpace(spec), "off") {
+		return nil, nil
+	}
+	var windows []Window
+	for _, part := range strings.Split(spec, ",") {
+		startText, endText, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid window %q, expected HH:MM-HH:MM", strings.TrimSpace(part))
+		}
+		start, err := parseClock(strings.TrimSpace(startText))
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(strings.TrimSpace(endText))
+		if err != nil {
+			return nil, err
+		}
+		if start >= end {
+			return nil, fmt.Errorf("window %q must start before it ends", strings.TrimSpace(part))
+		}
+		if len(windows) > 0 && start < windows[len(windows)-1].End {
+			return nil, fmt.Errorf("window %q overlaps or precedes the one before it", strings.TrimSpace(part))
+		}
+		windows = append(windows, Window{Start: start, End: end})
 	}
+	return windows, nil
+}
 
-	// Validate essential configuration
-	if cfg.LinkedIn.Username == "" || cfg.LinkedIn.Password == "" {
-		return nil, fmt.Errorf("linkedin username and password must be provided (either in config file or via environment variables LINKEDIN_AUTOMATION_LINKEDIN_USERNAME and LINKEDIN_AUTOMATION_LINKEDIN_PASSWORD)")
+// Location returns the account's timezone.
+func (s ScheduleConfig) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
 	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", s.Timezone, err)
+	}
+	return loc, nil
+}
 
-	return &cfg, nil
+// Contains reports whether t falls inside the working hours.
+func (w WorkingHours) Contains(t time.Time) bool {
+	if w.Start == "" && w.End == "" {
+		return true
+	}
+	start, errStart := parseClock(w.Start)
+	end, errEnd := parseClock(w.End)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	return minute >= start && minute < end
+}
+
+// parseClock converts "HH:MM" into minutes since midnight.
+func parseClock(s string) (int, error) {
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// LoggingConfig controls log output.
+type LoggingConfig struct {
+	// Level is "info" or "debug"; debug adds the source location to each line.
+	Level string `mapstructure:"level"`
+	// File receives the log output in addition to stderr when set.
+	File string `mapstructure:"file"`
+}
+
+// setDefaults registers the default value of every config key.
+// Durations are given as strings so they print the way users write them.
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("account", "")
+
+	v.SetDefault("linkedin.username", "")
+	v.SetDefault("linkedin.password", "")
+	v.SetDefault("linkedin.password_file", "")
+	v.SetDefault("linkedin.password_source", "")
+
+	v.SetDefault("storage.db_path", "linkedin_automation.db")
+	v.SetDefault("storage.artifacts_dir", "run-artifacts")
+	v.SetDefault("session.cookie_path", "linkedin_cookies.json")
+
+	v.SetDefault("limits.daily_connections", 100)
+	v.SetDefault("limits.daily_messages", 50)
+	v.SetDefault("limits.note_max_length", 300)
+	v.SetDefault("limits.note_overflow", "reject")
+	v.SetDefault("limits.message_max_length", 8000)
+	v.SetDefault("limits.message_overflow", "reject")
+	v.SetDefault("limits.invitation_limit_backoff", "24h")
+
+	v.SetDefault("pacing.between_connections.min", "5s")
+	v.SetDefault("pacing.between_connections.max", "15s")
+	v.SetDefault("pacing.between_messages.min", "10s")
+	v.SetDefault("pacing.between_messages.max", "30s")
+
+	v.SetDefault("browser.headless", true)
+	v.SetDefault("browser.bin", "")
+	v.SetDefault("browser.profile_dir", "")
+
+	v.SetDefault("search.job_title", "Software Engineer")
+	v.SetDefault("search.keywords", []string{"Go", "Golang"})
+	v.SetDefault("search.current_companies", []string{})
+	v.SetDefault("search.past_companies", []string{})
+	v.SetDefault("search.locations", []string{"San Francisco Bay Area"})
+	v.SetDefault("search.network", []int{})
+	v.SetDefault("search.industries", []string{})
+	v.SetDefault("search.schools", []string{})
+	v.SetDefault("search.profile_languages", []string{})
+	v.SetDefault("search.page_limit", 1)
+	v.SetDefault("search.facet_table_file", "search_facets.json")
+	v.SetDefault("search.cache_ttl", "24h")
+
+	v.SetDefault("campaign.name", "default")
+	v.SetDefault("campaign.exclude.first_degree", true)
+	v.SetDefault("campaign.exclude.already_contacted", true)
+	v.SetDefault("campaign.exclude.companies", []string{})
+	v.SetDefault("campaign.exclude.headline_keywords", []string{})
+	v.SetDefault("campaign.exclude.locations", []string{})
+	v.SetDefault("campaign.variables", map[string]string{})
+	v.SetDefault("campaign.scoring.title_keywords", map[string]float64{})
+	v.SetDefault("campaign.scoring.seniority", map[string]float64{})
+	v.SetDefault("campaign.scoring.locations", map[string]float64{})
+	v.SetDefault("campaign.scoring.companies", map[string]float64{})
+	v.SetDefault("campaign.scoring.mutual_connection_weight", 1.0)
+	v.SetDefault("campaign.scoring.max_mutual_connections", 10)
+
+	v.SetDefault("budget.result_pages", 0)
+	v.SetDefault("budget.profile_visits", 0)
+
+	v.SetDefault("retry.attempts", 3)
+	v.SetDefault("retry.initial_delay", "2s")
+	v.SetDefault("retry.max_delay", "30s")
+	v.SetDefault("retry.attempt_timeout", "2m")
+
+	v.SetDefault("templates.connection_note", "Hi, I came across your profile and was impressed by your work in Go. I'd love to connect!")
+	v.SetDefault("templates.follow_up", "Hello {{Name}}, thanks for connecting! I'm {{MyName}}, a {{MyTitle}}. I was particularly interested in your work on {{Interest}}. Let's chat more about it sometime.")
+	v.SetDefault("templates.variables", map[string]string{
+		"MyName":   "Your Name",
+		"MyTitle":  "Your Job Title",
+		"Interest": "Go-based automation tools",
+	})
+
+	v.SetDefault("schedule.paused", false)
+	v.SetDefault("schedule.working_hours.start", "")
+	v.SetDefault("schedule.working_hours.end", "")
+	v.SetDefault("schedule.timezone", "")
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		v.SetDefault("schedule.days."+day, "")
+	}
+	v.SetDefault("schedule.holidays_file", "")
+	v.SetDefault("schedule.spread", false)
+
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.file", "")
+}
+
+// FileSettings reads the config file at path on its own, without defaults or
+// environment overrides, and returns its settings with secrets redacted.
+func FileSettings(path string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	for _, key := range v.AllKeys() {
+		if (key == "linkedin.password" || strings.HasPrefix(key, "accounts.") && strings.HasSuffix(key, ".password")) && v.GetString(key) != "" {
+			v.Set(key, RedactedValue)
+		}
+	}
+	return v.AllSettings(), nil
 }
 
-// SaveConfig writes the current configuration to a file (optional, for persistent changes)
+// resolveSecrets fills LinkedIn.Password from password_file or password_source
+// when no password was given directly.
+func (c *Config) resolveSecrets() error {
+	if c.LinkedIn.Password != "" {
+		return nil
+	}
+	source := c.LinkedIn.PasswordSource
+	if c.LinkedIn.PasswordFile != "" {
+		if source != "" {
+			return fmt.Errorf("linkedin.password_file and linkedin.password_source are mutually exclusive")
+		}
+		source = "file:" + c.LinkedIn.PasswordFile
+	}
+	if source == "" {
+		return nil
+	}
+	password, err := ResolveSecret(source)
+	if err != nil {
+		return fmt.Errorf("failed to resolve linkedin password: %w", err)
+	}
+	c.LinkedIn.Password = password
+	return nil
+}
+
+// Redacted returns a copy of the configuration with every secret replaced by
+// RedactedValue, safe for logging or displaying.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	if redacted.LinkedIn.Password != "" {
+		redacted.LinkedIn.Password = RedactedValue
+	}
+	if c.Accounts != nil {
+		redacted.Accounts = make(map[string]AccountConfig, len(c.Accounts))
+		for name, account := range c.Accounts {
+			if account.Password != "" {
+				account.Password = RedactedValue
+			}
+			redacted.Accounts[name] = account
+		}
+	}
+	return &redacted
+}
+
+// String formats the configuration with secrets redacted, so a config passed to
+// log.Printf("%v") never leaks a password.
+func (c *Config) String() string {
+	return fmt.Sprintf("%+v", *c.Redacted())
+}
+
+// MarshalYAML renders the configuration under its config-file keys, with
+// secrets redacted and durations written as "5s" rather than nanoseconds.
+func (c *Config) MarshalYAML() (interface{}, error) {
+	return settingsOf(reflect.ValueOf(*c.Redacted())), nil
+}
+
+// settingsOf converts a config struct into a nested map keyed by the
+// mapstructure tags, i.e. the same shape as the config file.
+func settingsOf(v reflect.Value) map[string]interface{} {
+	settings := make(map[string]interface{})
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			settings[key] = settingsOf(field)
+		case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.Struct:
+			entries := make(map[string]interface{}, field.Len())
+			for _, name := range field.MapKeys() {
+				entries[name.String()] = settingsOf(field.MapIndex(name))
+			}
+			settings[key] = entries
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			settings[key] = field.Interface().(time.Duration).String()
+		default:
+			settings[key] = field.Interface()
+		}
+	}
+	return settings
+}
+
+// SaveConfig writes the current configuration to a file (optional, for persistent changes).
+// Secrets are never persisted: only references such as password_file and
+// password_source are written, never the resolved password.
 func SaveConfig(cfg *Config, filePath string) error {
-	viper.Set("linkedin.username", cfg.LinkedIn.Username)
-	viper.Set("linkedin.password", cfg.LinkedIn.Password)
-	// Set other fields if needed
+	settings := settingsOf(reflect.ValueOf(*cfg))
+	delete(settings["linkedin"].(map[string]interface{}), "password")
+	for _, account := range settings["accounts"].(map[string]interface{}) {
+		delete(account.(map[string]interface{}), "password")
+	}
+
+	// A fresh viper instance ensures nothing read from the environment or the
+	// original file (including a plaintext password) leaks into the output.
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("failed to prepare config for saving: %w", err)
+	}
 
 	// Ensure the directory exists
 	// For simplicity, we'll just write to the root for now,
 	// but in a real app, you'd want to handle paths properly.
-	return viper.WriteConfigAs(filePath)
+	return v.WriteConfigAs(filePath)
 }
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce lets editors finish writing (often several events for one
+// save) before the config is reloaded.
+const reloadDebounce = 500 * time.Millisecond
+
+// LimitsSource provides the current limits. Components read limits through it
+// on every use, so live config reloads take effect without a restart.
+type LimitsSource interface {
+	Limits() LimitsConfig
+}
+
+// StaticLimits is a LimitsSource that never changes.
+type StaticLimits LimitsConfig
+
+// Limits returns the fixed limits.
+func (s StaticLimits) Limits() LimitsConfig {
+	return LimitsConfig(s)
+}
+
+// Live holds the current configuration of a long-running process and reloads
+// it when the config files change. Only safe sections (limits, pacing,
+// templates and schedule) are applied live; anything else needs a restart.
+// An invalid edit is rejected and the last good configuration is kept.
+type Live struct {
+	loader *Loader
+
+	mu  sync.RWMutex
+	cfg *Config
+}
+
+// NewLive wraps cfg, the configuration loader last produced.
+func NewLive(loader *Loader, cfg *Config) *Live {
+	return &Live{loader: loader, cfg: cfg}
+}
+
+// Current returns the current configuration. Callers must not modify it.
+func (lv *Live) Current() *Config {
+	lv.mu.RLock()
+	defer lv.mu.RUnlock()
+	return lv.cfg
+}
+
+// Limits returns the current limits.
+func (lv *Live) Limits() LimitsConfig {
+	return lv.Current().Limits
+}
+
+// Watch reloads the configuration whenever one of the loader's files changes,
+// until stop is called.
+func (lv *Live) Watch() (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	// Directories are watched rather than files because editors often save
+	// by replacing the file, which drops a watch on the file itself.
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, path := range lv.loader.FilesUsed() {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to resolve config path %s: %w", path, err)
+		}
+		files[abs] = true
+		dirs[filepath.Dir(abs)] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		var debounce <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if abs, err := filepath.Abs(event.Name); err == nil && files[abs] && !event.Has(fsnotify.Chmod) {
+					debounce = time.After(reloadDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Config watcher error: %v", err)
+			case <-debounce:
+				debounce = nil
+				lv.Reload()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	log.Printf("Watching %d config file(s) for changes.", len(files))
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			watcher.Close()
+		})
+	}, nil
+}
+
+// Reload re-reads the configuration and applies its safe sections. It
+// returns an error, and keeps the current configuration, if the new one is
+// invalid.
+func (lv *Live) Reload() error {
+	next, err := lv.loader.Load()
+	if err != nil {
+		log.Printf("Rejected config change, keeping the last good configuration: %v", err)
+		return err
+	}
+
+	lv.mu.Lock()
+	prev := lv.cfg
+	applied := *prev
+	applied.Limits = next.Limits
+	applied.Pacing = next.Pacing
+	applied.Templates = next.Templates
+	applied.Schedule = next.Schedule
+	lv.cfg = &applied
+	lv.mu.Unlock()
+
+	changes := diffSettings(prev, &applied)
+	for _, change := range changes {
+		log.Printf("Config reloaded: %s", change)
+	}
+	if ignored := diffSettings(&applied, next); len(ignored) > 0 {
+		for _, change := range ignored {
+			log.Printf("Config change needs a restart to take effect: %s", change)
+		}
+	} else if len(changes) == 0 {
+		log.Println("Config file changed, but no settings differ.")
+	}
+	return nil
+}
+
+// diffSettings describes every setting that differs between a and b, with
+// secrets redacted.
+func diffSettings(a, b *Config) []string {
+	before := flattenSettings(settingsOf(reflect.ValueOf(*a.Redacted())), "")
+	after := flattenSettings(settingsOf(reflect.ValueOf(*b.Redacted())), "")
+
+	var changes []string
+	for key, value := range after {
+		if old := before[key]; old != value {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", key, old, value))
+		}
+	}
+	for key, old := range before {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, fmt.Sprintf("%s: %s -> (unset)", key, old))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// flattenSettings turns nested settings into dotted keys with printable values.
+func flattenSettings(settings map[string]interface{}, prefix string) map[string]string {
+	flat := make(map[string]string)
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, val := range flattenSettings(v, prefix+key+".") {
+				flat[k] = val
+			}
+		case map[string]string:
+			for k, val := range v {
+				flat[prefix+key+"."+k] = fmt.Sprintf("%q", val)
+			}
+		default:
+			flat[prefix+key] = fmt.Sprintf("%#v", v)
+		}
+	}
+	return flat
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is the prefix of environment variables that override config keys,
+// e.g. LINKEDIN_AUTOMATION_LIMITS_DAILY_CONNECTIONS.
+const EnvPrefix = "LINKEDIN_AUTOMATION"
+
+// Loader builds a Config from layered sources using its own viper instance,
+// so several configs can be loaded side by side.
+//
+// Precedence, highest first: overrides (command-line flags), environment
+// variables, overlay files (later files win), the base file, defaults.
+type Loader struct {
+	// ConfigFile is the base config file. When empty, config.yaml is looked
+	// up in the current directory and in ./config.
+	ConfigFile string
+	// OverlayFiles are merged over the base file in order, e.g. an account
+	// file followed by a campaign file.
+	OverlayFiles []string
+	// Account selects a named account profile from the accounts section. When
+	// empty, the "account" key from the files or environment is used.
+	Account string
+
+	overrides map[string]interface{}
+	v         *viper.Viper
+	filesUsed []string
+}
+
+// NewLoader creates a Loader that reads the default config file locations.
+func NewLoader() *Loader {
+	return &Loader{overrides: make(map[string]interface{})}
+}
+
+// Set overrides a config key (e.g. "limits.daily_connections") above every
+// other source. It is meant for command-line flags.
+func (l *Loader) Set(key string, value interface{}) {
+	l.overrides[strings.ToLower(key)] = value
+}
+
+// FilesUsed returns the config files read by the last Load, base file first.
+func (l *Loader) FilesUsed() []string {
+	return l.filesUsed
+}
+
+// Load reads every source, applies defaults and validates the result. A
+// *ValidationError lists every problem found, including unknown keys.
+func (l *Loader) Load() (*Config, error) {
+	v := viper.New()
+	l.v = v
+	l.filesUsed = nil
+
+	setDefaults(v)
+
+	if l.ConfigFile != "" {
+		v.SetConfigFile(l.ConfigFile)
+	} else {
+		v.SetConfigName("config")   // name of config file (without extension)
+		v.SetConfigType("yaml")     // or "json"
+		v.AddConfigPath(".")        // path to look for the config file in the current directory
+		v.AddConfigPath("./config") // path to look for the config file in the config directory
+	}
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok && l.ConfigFile == "" {
+			// Config file not found; ignore error if we're relying solely on environment variables
+			log.Println("Config file not found, relying on environment variables or defaults.")
+		} else {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	} else {
+		l.filesUsed = append(l.filesUsed, v.ConfigFileUsed())
+	}
+
+	for _, path := range l.OverlayFiles {
+		if err := mergeFile(v, path); err != nil {
+			return nil, err
+		}
+		l.filesUsed = append(l.filesUsed, path)
+	}
+
+	// Read environment variables
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	account := strings.ToLower(l.Account)
+	if account == "" {
+		account = strings.ToLower(v.GetString("account"))
+	}
+	if err := applyAccount(v, account); err != nil {
+		return nil, err
+	}
+
+	for key, value := range l.overrides {
+		v.Set(key, value)
+	}
+	if account != "" {
+		v.Set("account", account)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	problems := unknownKeys(v)
+	if err := cfg.resolveSecrets(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	problems = append(problems, cfg.problems()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return &cfg, nil
+}
+
+// mergeFile merges the config file at path over the settings already in v.
+func mergeFile(v *viper.Viper, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config overlay: %w", err)
+	}
+	defer f.Close()
+
+	v.SetConfigType(strings.TrimPrefix(filepath.Ext(path), "."))
+	if err := v.MergeConfig(f); err != nil {
+		return fmt.Errorf("failed to merge config overlay %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadConfig reads configuration from the default config file locations and
+// environment variables. It is shorthand for NewLoader().Load().
+func LoadConfig() (*Config, error) {
+	return NewLoader().Load()
+}
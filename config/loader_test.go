@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// baseYAML is the smallest base config that passes validation.
+const baseYAML = `
+linkedin:
+  username: "base@example.com"
+  password: "base-secret"
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		campaign string
+		env      string
+		flag     int
+		want     int
+	}{
+		{name: "default", want: 100},
+		{name: "base file", base: "limits:\n  daily_connections: 10\n", want: 10},
+		{name: "campaign over base", base: "limits:\n  daily_connections: 10\n", campaign: "limits:\n  daily_connections: 15\n", want: 15},
+		{name: "env over campaign", base: "limits:\n  daily_connections: 10\n", campaign: "limits:\n  daily_connections: 15\n", env: "20", want: 20},
+		{name: "flag over env", base: "limits:\n  daily_connections: 10\n", campaign: "limits:\n  daily_connections: 15\n", env: "20", flag: 25, want: 25},
+		{name: "flag over defaults", flag: 25, want: 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			loader := NewLoader()
+			loader.ConfigFile = writeFile(t, dir, "config.yaml", baseYAML+tt.base)
+			if tt.campaign != "" {
+				loader.OverlayFiles = append(loader.OverlayFiles, writeFile(t, dir, "campaign.yaml", tt.campaign))
+			}
+			if tt.env != "" {
+				t.Setenv(EnvPrefix+"_LIMITS_DAILY_CONNECTIONS", tt.env)
+			}
+			if tt.flag != 0 {
+				loader.Set("limits.daily_connections", tt.flag)
+			}
+
+			cfg, err := loader.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Limits.DailyConnections != tt.want {
+				t.Errorf("limits.daily_connections = %d, want %d", cfg.Limits.DailyConnections, tt.want)
+			}
+			// Keys no layer sets keep their defaults.
+			if cfg.Limits.DailyMessages != 50 {
+				t.Errorf("limits.daily_messages = %d, want the default 50", cfg.Limits.DailyMessages)
+			}
+		})
+	}
+}
+
+func TestLoadOverlayFilesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	loader := NewLoader()
+	loader.ConfigFile = writeFile(t, dir, "config.yaml", baseYAML)
+	loader.OverlayFiles = []string{
+		writeFile(t, dir, "account.yaml", "limits:\n  daily_connections: 30\n  daily_messages: 30\n"),
+		writeFile(t, dir, "campaign.yaml", "limits:\n  daily_connections: 40\n"),
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Limits.DailyConnections != 40 || cfg.Limits.DailyMessages != 30 {
+		t.Errorf("limits = %d connections, %d messages; want 40 and 30", cfg.Limits.DailyConnections, cfg.Limits.DailyMessages)
+	}
+	if got := loader.FilesUsed(); len(got) != 3 {
+		t.Errorf("FilesUsed = %v, want the base file and both overlays", got)
+	}
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	loader := NewLoader()
+	loader.ConfigFile = writeFile(t, t.TempDir(), "config.yaml", baseYAML+"limits:\n  daily_conections: 10\n")
+	_, err := loader.Load()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), `unknown config key "limits.daily_conections"`) {
+		t.Errorf("got %v, want a validation error naming the misspelt key", err)
+	}
+}
+
+// accountsYAML configures two account profiles over the base credentials.
+const accountsYAML = `
+accounts:
+  work:
+    username: "work@example.com"
+    password: "work-secret"
+  personal:
+    username: "me@example.com"
+    password_file: "%s"
+    db_path: "personal.db"
+`
+
+func TestLoadAccount(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := writeFile(t, dir, "password", "personal-secret\n")
+	configFile := writeFile(t, dir, "config.yaml", baseYAML+strings.Replace(accountsYAML, "%s", filepath.ToSlash(passwordFile), 1))
+
+	loader := NewLoader()
+	loader.ConfigFile = configFile
+	loader.Account = "Personal"
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Account != "personal" {
+		t.Errorf("Account = %q, want %q", cfg.Account, "personal")
+	}
+	if cfg.LinkedIn.Username != "me@example.com" || cfg.LinkedIn.Password != "personal-secret" {
+		t.Errorf("credentials = %q/%q, want the personal account's", cfg.LinkedIn.Username, cfg.LinkedIn.Password)
+	}
+	if cfg.Storage.DBPath != "personal.db" {
+		t.Errorf("storage.db_path = %q, want the account's own path", cfg.Storage.DBPath)
+	}
+	if want := filepath.Join(AccountsDir, "personal", "linkedin_cookies.json"); cfg.Session.CookiePath != want {
+		t.Errorf("session.cookie_path = %q, want %q", cfg.Session.CookiePath, want)
+	}
+	if want := filepath.Join(AccountsDir, "personal", "browser-profile"); cfg.Browser.ProfileDir != want {
+		t.Errorf("browser.profile_dir = %q, want %q", cfg.Browser.ProfileDir, want)
+	}
+}
+
+func TestLoadAccountFromFileWithEnvAndFlags(t *testing.T) {
+	dir := t.TempDir()
+	loader := NewLoader()
+	loader.ConfigFile = writeFile(t, dir, "config.yaml", baseYAML+"account: work\n"+strings.Replace(accountsYAML, "%s", "unused", 1))
+	t.Setenv(EnvPrefix+"_STORAGE_DB_PATH", "env.db")
+	loader.Set("session.cookie_path", "flag_cookies.json")
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Account != "work" || cfg.LinkedIn.Username != "work@example.com" {
+		t.Errorf("account %q with username %q, want the work account selected by the file", cfg.Account, cfg.LinkedIn.Username)
+	}
+	if cfg.Storage.DBPath != "env.db" {
+		t.Errorf("storage.db_path = %q, want the environment to win over the account", cfg.Storage.DBPath)
+	}
+	if cfg.Session.CookiePath != "flag_cookies.json" {
+		t.Errorf("session.cookie_path = %q, want the flag to win over the account", cfg.Session.CookiePath)
+	}
+}
+
+func TestLoadAccountProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts string
+		account  string
+		want     string
+	}{
+		{
+			name:     "none selected",
+			accounts: "accounts:\n  work:\n    username: w\n",
+			want:     "select one with --account (one of: work)",
+		},
+		{
+			name:     "unknown",
+			accounts: "accounts:\n  work:\n    username: w\n",
+			account:  "nope",
+			want:     `unknown account "nope" (configured: work)`,
+		},
+		{
+			name:    "no accounts configured",
+			account: "work",
+			want:    `account "work" selected but no accounts are configured`,
+		},
+		{
+			name:     "shared database",
+			accounts: "accounts:\n  a:\n    db_path: same.db\n  b:\n    db_path: same.db\n",
+			account:  "a",
+			want:     `accounts "a" and "b" share the same db_path same.db`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := NewLoader()
+			loader.ConfigFile = writeFile(t, t.TempDir(), "config.yaml", baseYAML+tt.accounts)
+			loader.Account = tt.account
+			_, err := loader.Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
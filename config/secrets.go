@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// RedactedValue replaces secrets whenever a config is logged or displayed.
+const RedactedValue = "[REDACTED]"
+
+// SecretProvider resolves a secret reference (the part after "scheme:" in a
+// password_source value) into the secret itself.
+type SecretProvider interface {
+	Resolve(ref string) (string, error)
+}
+
+// SecretProviderFunc adapts a plain function to the SecretProvider interface.
+type SecretProviderFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f SecretProviderFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// Keyring is the minimal OS keyring surface the keyring provider needs.
+// It is an interface so a local stand-in (see MemoryKeyring) can replace the
+// real OS keyring on machines without one.
+type Keyring interface {
+	Get(service, account string) (string, error)
+}
+
+// MemoryKeyring is an in-memory Keyring keyed by "service/account".
+type MemoryKeyring map[string]string
+
+// Get returns the secret stored for service/account.
+func (k MemoryKeyring) Get(service, account string) (string, error) {
+	secret, ok := k[service+"/"+account]
+	if !ok {
+		return "", fmt.Errorf("no keyring entry for %s/%s", service, account)
+	}
+	return secret, nil
+}
+
+// SystemKeyring reads secrets from the OS keyring using the platform's own
+// command-line tools (secret-tool on Linux, security on macOS), so no cgo
+// keyring bindings are required.
+type SystemKeyring struct{}
+
+// Get looks up the secret for service/account in the OS keyring.
+func (SystemKeyring) Get(service, account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	default:
+		return "", fmt.Errorf("OS keyring is not supported on %s; use a file, env or exec password source instead", runtime.GOOS)
+	}
+	return runSecretCommand(cmd)
+}
+
+// secretProviders maps a password_source scheme to its provider.
+var secretProviders = map[string]SecretProvider{}
+
+// keyring is the Keyring used by the "keyring" provider.
+var keyring Keyring = SystemKeyring{}
+
+func init() {
+	RegisterSecretProvider("env", SecretProviderFunc(resolveEnvSecret))
+	RegisterSecretProvider("file", SecretProviderFunc(resolveFileSecret))
+	RegisterSecretProvider("keyring", SecretProviderFunc(resolveKeyringSecret))
+	RegisterSecretProvider("exec", SecretProviderFunc(resolveExecSecret))
+}
+
+// RegisterSecretProvider makes a provider available under the given scheme,
+// replacing any provider already registered for it.
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProviders[scheme] = provider
+}
+
+// SetKeyring swaps the Keyring used by the "keyring" provider.
+func SetKeyring(k Keyring) {
+	keyring = k
+}
+
+// ResolveSecret resolves a "scheme:reference" string, e.g. "env:LI_PASSWORD",
+// "file:~/.secrets/linkedin", "keyring:linkedin-automation/me@example.com" or
+// "exec:pass show linkedin".
+func ResolveSecret(source string) (string, error) {
+	scheme, ref, ok := strings.Cut(source, ":")
+	if !ok || ref == "" {
+		return "", fmt.Errorf("invalid secret source %q: expected scheme:reference", source)
+	}
+	provider, ok := secretProviders[scheme]
+	if !ok {
+		return "", fmt.Errorf("unknown secret source scheme %q", scheme)
+	}
+	secret, err := provider.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret: %w", scheme, err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("%s secret source resolved to an empty value", scheme)
+	}
+	return secret, nil
+}
+
+// resolveEnvSecret reads the secret from the named environment variable.
+func resolveEnvSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// resolveFileSecret reads the secret from a file, ignoring a trailing newline.
+func resolveFileSecret(path string) (string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveKeyringSecret reads a "service/account" entry from the keyring.
+func resolveKeyringSecret(ref string) (string, error) {
+	service, account, ok := strings.Cut(ref, "/")
+	if !ok || service == "" || account == "" {
+		return "", fmt.Errorf("keyring reference %q must be service/account", ref)
+	}
+	return keyring.Get(service, account)
+}
+
+// resolveExecSecret runs a command and uses its standard output as the secret.
+// The command line is split on whitespace; no shell is involved.
+func resolveExecSecret(command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("empty exec command")
+	}
+	return runSecretCommand(exec.Command(args[0], args[1:]...))
+}
+
+// runSecretCommand runs cmd and returns its trimmed standard output.
+func runSecretCommand(cmd *exec.Cmd) (string, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w (%s)", cmd.Path, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	return home + path[1:], nil
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"linkedin-automation/holidays"  // Import holidays to check the holiday calendar
+	"linkedin-automation/textlimit" // Import textlimit to count characters like the site
+)
+
+// ValidationError reports every problem found in a configuration at once,
+// so users can fix their config file in a single pass.
+type ValidationError struct {
+	Problems []string
+}
+
+// Error lists each problem on its own line.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problem(s)):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks the configuration values and returns a *ValidationError
+// listing every problem, or nil if the configuration is usable.
+func (c *Config) Validate() error {
+	if problems := c.problems(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// problems returns a human-readable description of each invalid value.
+func (c *Config) problems() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	problems = append(problems, c.accountProblems()...)
+	if c.LinkedIn.Username == "" {
+		add("linkedin.username is required (or set LINKEDIN_AUTOMATION_LINKEDIN_USERNAME)")
+	}
+	if c.LinkedIn.Password == "" {
+		add("a linkedin password is required: set linkedin.password_file, linkedin.password_source or LINKEDIN_AUTOMATION_LINKEDIN_PASSWORD")
+	}
+	if c.Storage.DBPath == "" {
+		add("storage.db_path must not be empty")
+	}
+	if c.Session.CookiePath == "" {
+		add("session.cookie_path must not be empty")
+	}
+
+	if c.Limits.DailyConnections < 1 {
+		add("limits.daily_connections must be at least 1, got %d", c.Limits.DailyConnections)
+	}
+	if c.Limits.DailyMessages < 1 {
+		add("limits.daily_messages must be at least 1, got %d", c.Limits.DailyMessages)
+	}
+	if c.Limits.NoteMaxLength < 1 || c.Limits.NoteMaxLength > 300 {
+		add("limits.note_max_length must be between 1 and 300, got %d", c.Limits.NoteMaxLength)
+	}
+	if c.Limits.MessageMaxLength < 1 || c.Limits.MessageMaxLength > 8000 {
+		add("limits.message_max_length must be between 1 and 8000, got %d", c.Limits.MessageMaxLength)
+	}
+	checkOverflow := func(key, overflow string) {
+		if overflow != string(textlimit.Reject) && overflow != string(textlimit.Truncate) {
+			add("%s must be %q or %q, got %q", key, textlimit.Reject, textlimit.Truncate, overflow)
+		}
+	}
+	checkOverflow("limits.note_overflow", c.Limits.NoteOverflow)
+	checkOverflow("limits.message_overflow", c.Limits.MessageOverflow)
+	if c.Limits.InvitationLimitBackoff < 0 {
+		add("limits.invitation_limit_backoff must not be negative, got %s", c.Limits.InvitationLimitBackoff)
+	}
+
+	checkDelay := func(key string, r DelayRange) {
+		if r.Min < 0 || r.Max < 0 {
+			add("%s: delays must not be negative (min %s, max %s)", key, r.Min, r.Max)
+		} else if r.Min > r.Max {
+			add("%s.min (%s) must not exceed %s.max (%s)", key, r.Min, key, r.Max)
+		}
+	}
+	checkDelay("pacing.between_connections", c.Pacing.BetweenConnections)
+	checkDelay("pacing.between_messages", c.Pacing.BetweenMessages)
+
+	if c.Search.PageLimit < 1 {
+		add("search.page_limit must be at least 1, got %d", c.Search.PageLimit)
+	}
+	if c.Search.JobTitle == "" && len(c.Search.Keywords) == 0 && len(c.Search.CurrentCompanies) == 0 &&
+		len(c.Search.PastCompanies) == 0 && len(c.Search.Locations) == 0 && len(c.Search.Industries) == 0 &&
+		len(c.Search.Schools) == 0 && len(c.Search.Network) == 0 && len(c.Search.ProfileLanguages) == 0 {
+		add("search needs at least one of job_title, keywords or a facet (current_companies, locations, ...)")
+	}
+	if c.Search.CacheTTL < 0 {
+		add("search.cache_ttl must not be negative, got %s", c.Search.CacheTTL)
+	}
+	for _, degree := range c.Search.Network {
+		if degree < 1 || degree > 3 {
+			add("search.network entries must be 1, 2 or 3, got %d", degree)
+		}
+	}
+
+	if strings.TrimSpace(c.Campaign.Name) == "" {
+		add("campaign.name must not be empty")
+	}
+	if c.Campaign.Scoring.MaxMutualConnections < 0 {
+		add("campaign.scoring.max_mutual_connections must not be negative, got %d", c.Campaign.Scoring.MaxMutualConnections)
+	}
+
+	if c.Budget.ResultPages < 0 {
+		add("budget.result_pages must not be negative (0 means unlimited), got %d", c.Budget.ResultPages)
+	}
+	if c.Budget.ProfileVisits < 0 {
+		add("budget.profile_visits must not be negative (0 means unlimited), got %d", c.Budget.ProfileVisits)
+	}
+
+	if c.Retry.Attempts < 1 {
+		add("retry.attempts must be at least 1, got %d", c.Retry.Attempts)
+	}
+	if c.Retry.AttemptTimeout < 0 {
+		add("retry.attempt_timeout must not be negative, got %s", c.Retry.AttemptTimeout)
+	}
+	if c.Retry.InitialDelay < 0 || c.Retry.MaxDelay < 0 {
+		add("retry: delays must not be negative (initial_delay %s, max_delay %s)", c.Retry.InitialDelay, c.Retry.MaxDelay)
+	} else if c.Retry.InitialDelay > c.Retry.MaxDelay {
+		add("retry.initial_delay (%s) must not exceed retry.max_delay (%s)", c.Retry.InitialDelay, c.Retry.MaxDelay)
+	}
+
+	if strings.TrimSpace(c.Templates.ConnectionNote) == "" {
+		add("templates.connection_note must not be empty")
+	}
+	// The length of a note is only known once it is rendered for a profile,
+	// so limits.note_max_length is enforced per invitation, not here.
+	if strings.TrimSpace(c.Templates.FollowUp) == "" {
+		add("templates.follow_up must not be empty")
+	}
+
+	if wh := c.Schedule.WorkingHours; wh.Start != "" || wh.End != "" {
+		start, errStart := parseClock(wh.Start)
+		end, errEnd := parseClock(wh.End)
+		switch {
+		case errStart != nil:
+			add("schedule.working_hours.start: %v", errStart)
+		case errEnd != nil:
+			add("schedule.working_hours.end: %v", errEnd)
+		case start >= end:
+			add("schedule.working_hours.start (%s) must be before end (%s)", wh.Start, wh.End)
+		}
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if spec := c.Schedule.Days.Get(day); strings.TrimSpace(spec) != "" {
+			if _, err := ParseWindows(spec); err != nil {
+				add("schedule.days.%s: %v", strings.ToLower(day.String()), err)
+			}
+		}
+	}
+	loc, err := c.Schedule.Location()
+	if err != nil {
+		add("schedule.timezone: %v", err)
+		loc = time.Local
+	}
+	if c.Schedule.HolidaysFile != "" {
+		if _, err := holidays.Load(c.Schedule.HolidaysFile, loc); err != nil {
+			add("schedule.holidays_file: %v", err)
+		}
+	}
+
+	switch c.Logging.Level {
+	case "info", "debug":
+	default:
+		add("logging.level must be \"info\" or \"debug\", got %q", c.Logging.Level)
+	}
+
+	return problems
+}
+
+// unknownKeys reports every key set in the config file that is not part of
+// the Config schema, catching typos that would otherwise be silently ignored.
+func unknownKeys(v *viper.Viper) []string {
+	known, open := schemaKeys(reflect.TypeOf(Config{}), "")
+
+	var problems []string
+	for _, key := range v.AllKeys() {
+		if known[key] || matchesAnyPattern(key, known) || underOpenKey(key, open) {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("unknown config key %q", key))
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// schemaKeys walks the Config struct and returns its leaf keys, plus the keys
+// of map-valued fields whose children are free-form. Keys under a map of
+// structs (such as accounts) use "*" for the map key segment.
+func schemaKeys(t reflect.Type, prefix string) (known map[string]bool, open []string) {
+	known = make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			if field.Type.PkgPath() == "time" {
+				known[key] = true
+				continue
+			}
+			childKnown, childOpen := schemaKeys(field.Type, key+".")
+			for k := range childKnown {
+				known[k] = true
+			}
+			open = append(open, childOpen...)
+		case reflect.Map:
+			known[key] = true
+			if field.Type.Elem().Kind() == reflect.Struct {
+				childKnown, _ := schemaKeys(field.Type.Elem(), key+".*.")
+				for k := range childKnown {
+					known[k] = true
+				}
+				continue
+			}
+			open = append(open, key)
+		default:
+			known[key] = true
+		}
+	}
+	return known, open
+}
+
+// matchesAnyPattern reports whether key matches a known key containing "*"
+// segments.
+func matchesAnyPattern(key string, known map[string]bool) bool {
+	segments := strings.Split(key, ".")
+	for pattern := range known {
+		if !strings.Contains(pattern, "*") {
+			continue
+		}
+		patternSegments := strings.Split(pattern, ".")
+		if len(patternSegments) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, segment := range patternSegments {
+			if segment != "*" && segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// underOpenKey reports whether key is a child of one of the open keys.
+func underOpenKey(key string, open []string) bool {
+	for _, prefix := range open {
+		if strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+
+	"linkedin-automation/config"
+)
+
+const configUsage = `usage:
+  linkedin-automation [flags] config validate
+  linkedin-automation [flags] config print [--effective]`
+
+// runConfigCommand implements the "config" subcommands and returns the
+// process exit code.
+func runConfigCommand(loader *config.Loader, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "validate":
+		return runConfigValidate(loader)
+	case "print":
+		return runConfigPrint(loader, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config command %q\n%s\n", args[0], configUsage)
+		return 2
+	}
+}
+
+// runConfigValidate loads the configuration and reports every problem found.
+func runConfigValidate(loader *config.Loader) int {
+	if _, err := loader.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("Configuration is valid.")
+	return 0
+}
+
+// runConfigPrint prints the settings of each config file read or, with
+// --effective, the fully merged configuration (defaults, files, environment
+// and -set overrides). Secrets are always redacted.
+func runConfigPrint(loader *config.Loader, args []string) int {
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	effective := fs.Bool("effective", false, "print the merged configuration including defaults and environment overrides")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := loader.Load()
+	var validationErr *config.ValidationError
+	if err != nil && (*effective || !errors.As(err, &validationErr)) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *effective {
+		return printYAML(cfg)
+	}
+
+	if len(loader.FilesUsed()) == 0 {
+		fmt.Fprintln(os.Stderr, "No config file found; use --effective to see defaults and environment overrides.")
+		return 1
+	}
+	for _, path := range loader.FilesUsed() {
+		settings, err := config.FileSettings(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("# %s\n", path)
+		if code := printYAML(settings); code != 0 {
+			return code
+		}
+	}
+	return 0
+}
+
+// printYAML writes v to stdout as YAML and returns the exit code.
+func printYAML(v interface{}) int {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to render config: %v\n", err)
+		return 1
+	}
+	fmt.Print(string(data))
+	return 0
+}
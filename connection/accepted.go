@@ -0,0 +1,91 @@
+package connection
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"linkedin-automation/search"  // Import search to normalize profile links
+	"linkedin-automation/stealth" // Import stealth for human-like interactions
+)
+
+// connectionsURL lists the member's connections, most recently added first.
+const connectionsURL = "https://www.linkedin.com/mynetwork/invite-connect/connections/"
+
+// connectionLinksJS returns the profile links of the connection cards loaded
+// on the connections page. Anything else linking to a profile is filtered out
+// by only marking invitations this tool sent.
+const connectionLinksJS = `() => Array.from(document.querySelectorAll('main a[href*="/in/"]'), a => a.href)`
+
+// DetectAcceptedInvitations opens the connections page and marks every
+// invitation this tool sent to someone listed there as accepted, so the
+// profile gets a follow-up. Only the most recent connections are loaded, which
+// is enough when it runs every pass. It returns how many invitations it
+// marked. Transient browser failures are retried according to Retry.
+func (cr *ConnectionRequester) DetectAcceptedInvitations() (int, error) {
+	if cr.Browser == nil {
+		return 0, fmt.Errorf("browser not launched")
+	}
+	var links []string
+	_, err := cr.Retry.Do("Reading recent connections", func(attempt int) (err error) {
+		links, err = cr.recentConnections()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	accepted := 0
+	seen := make(map[string]bool)
+	for _, link := range links {
+		profileURL, ok := search.NormalizeProfileURL(link)
+		if !ok || seen[profileURL] {
+			continue
+		}
+		seen[profileURL] = true
+		marked, err := cr.recordAccepted(profileURL)
+		if err != nil {
+			return accepted, err
+		}
+		if marked {
+			accepted++
+		}
+	}
+	return accepted, nil
+}
+
+// recentConnections makes one attempt at reading the profile links of the
+// connections page.
+func (cr *ConnectionRequester) recentConnections() (links []string, err error) {
+	cr.Page = nil
+	defer func() {
+		// Rod's Must helpers panic; turn that into an error so it can be retried
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = panicErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	page := cr.Browser.MustPage("")
+	defer page.Close()
+	var cancel func()
+	cr.Page, cancel = cr.Retry.WithTimeout(page)
+	defer cancel()
+	cr.Page.MustNavigate(connectionsURL).MustWaitLoad()
+	if err := stealth.ApplyPageStealth(cr.Page); err != nil {
+		log.Printf("Warning: Failed to apply stealth to connections page: %v", err)
+	}
+	stealth.RandomDelay(2*time.Second, 4*time.Second) // Let the cards render
+
+	obj, err := cr.Page.Eval(connectionLinksJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection cards: %w", err)
+	}
+	for _, link := range obj.Value.Arr() {
+		links = append(links, link.Str())
+	}
+	log.Printf("Found %d profile links on the connections page", len(links))
+	return links, nil
+}
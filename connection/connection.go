@@ -1,34 +1,68 @@
 package connection
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
-	"linkedin-automation/stealth" // Import stealth for human-like interactions
-	"linkedin-automation/storage" // Import storage for persistence
+	"linkedin-automation/artifacts" // Import artifacts to capture failures
+	"linkedin-automation/budget"    // Import budget to cap profile visits per run
+	"linkedin-automation/config"    // Import config for the live limits
+	"linkedin-automation/profile"   // Import profile to record profile details
+	"linkedin-automation/retry"     // Import retry to retry transient failures
+	"linkedin-automation/stealth"   // Import stealth for human-like interactions
+	"linkedin-automation/storage"   // Import storage for persistence
+	"linkedin-automation/templates" // Import templates to render notes
+	"linkedin-automation/textlimit" // Import textlimit to enforce the note length
 )
 
 // ConnectionRequester handles sending connection requests on LinkedIn.
 type ConnectionRequester struct {
 	Browser *rod.Browser
 	Page    *rod.Page
-	Storage *storage.Storage // Reference to storage for persistence
-	DailyLimit int // Example daily limit
+	Storage *storage.Storage    // Reference to storage for persistence
+	Budget  *budget.Budget      // Caps profile visits per run; nil is unlimited
+	Limits  config.LimitsSource // Read on every request so config reloads apply immediately
+	// Artifacts captures evidence of failed requests; nil disables it.
+	Artifacts *artifacts.Recorder
+	// Retry retries requests failing with transient browser errors; the zero value tries once.
+	Retry retry.Policy
 }
 
 // NewConnectionRequester creates a new ConnectionRequester instance.
-func NewConnectionRequester(browser *rod.Browser, store *storage.Storage) *ConnectionRequester {
+func NewConnectionRequester(browser *rod.Browser, store *storage.Storage, limits config.LimitsSource) *ConnectionRequester {
 	return &ConnectionRequester{
 		Browser: browser,
 		Storage: store,
-		DailyLimit: 100, // Default daily limit, can be configured
+		Limits:  limits,
 	}
 }
 
-// SendConnectionRequest navigates to a profile, clicks connect, and sends a personalized note.
-func (cr *ConnectionRequester) SendConnectionRequest(profileURL, note string) error {
+// ErrInvalidNote is returned when a connection note cannot be sent as
+// rendered: a placeholder is unresolved or it is over the length limit.
+var ErrInvalidNote = errors.New("invalid connection note")
+
+// ErrDailyLimit is returned when limits.daily_connections requests were
+// already sent today.
+var ErrDailyLimit = errors.New("daily connection request limit reached")
+
+// Note is a connection note template and the variables to fill it with.
+type Note struct {
+	TemplateID string // Stored with the request, see templates.ID
+	Template   string // Empty to connect without a note
+	// Variables are overridden by the details scraped from the profile,
+	// which in turn are overridden by Overrides (e.g. imported columns).
+	Variables map[string]string
+	Overrides map[string]string
+}
+
+// SendConnectionRequest navigates to a profile, clicks connect, and sends a
+// personalized note rendered from the profile's details. Transient browser
+// failures are retried according to Retry.
+func (cr *ConnectionRequester) SendConnectionRequest(profileURL string, note Note) error {
 	if cr.Browser == nil {
 		return fmt.Errorf("browser not launched")
 	}
@@ -44,15 +78,58 @@ func (cr *ConnectionRequester) SendConnectionRequest(profileURL, note string) er
 	}
 
 	// Check daily limit
+	limits := cr.Limits.Limits()
 	requestsToday, err := cr.Storage.GetCountOfSentRequestsToday()
 	if err != nil {
 		return fmt.Errorf("failed to get count of sent requests today: %w", err)
 	}
-	if requestsToday >= cr.DailyLimit {
-		return fmt.Errorf("daily connection request limit (%d) reached. Sent %d today.", cr.DailyLimit, requestsToday)
+	if requestsToday >= limits.DailyConnections {
+		return fmt.Errorf("%w (%d). Sent %d today.", ErrDailyLimit, limits.DailyConnections, requestsToday)
+	}
+	// Back off for a while after LinkedIn showed its own weekly limit
+	if err := cr.checkBackoff(limits.InvitationLimitBackoff); err != nil {
+		return err
 	}
 
-	cr.Page = cr.Browser.MustPage(profileURL).MustWaitLoad()
+	_, err = cr.Retry.Do("Connection request to "+profileURL, func(attempt int) error {
+		return cr.sendConnectionRequest(profileURL, note, attempt)
+	})
+	return err
+}
+
+// sendConnectionRequest makes one attempt (starting at 1) at visiting a
+// profile and sending the invitation.
+func (cr *ConnectionRequester) sendConnectionRequest(profileURL string, note Note, attempt int) (err error) {
+	if err := cr.Budget.SpendProfileVisit(); err != nil {
+		return err
+	}
+	limits := cr.Limits.Limits()
+	cr.Page = nil
+	var page *rod.Page // The tab without the attempt's deadline, still usable for evidence after a timeout
+	defer func() {
+		// Rod's Must helpers panic; turn that into an error so it can be retried
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = panicErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+		// Keep evidence of whatever went wrong on the profile page
+		if err != nil && page != nil {
+			cr.Artifacts.Capture(page, storage.ActionInvite, profileURL, attempt, err)
+		}
+		// Close the tab, so retries and later actions do not pile up open tabs
+		if page != nil {
+			page.Close()
+		}
+	}()
+	page = cr.Browser.MustPage("")
+	// Bound the attempt, so a profile that never finishes loading times out and is retried
+	var cancel func()
+	cr.Page, cancel = cr.Retry.WithTimeout(page)
+	defer cancel()
+	cr.Page.MustNavigate(profileURL).MustWaitLoad()
 	if err := stealth.ApplyPageStealth(cr.Page); err != nil {
 		log.Printf("Warning: Failed to apply stealth to connection page: %v", err)
 	}
@@ -60,28 +137,69 @@ func (cr *ConnectionRequester) SendConnectionRequest(profileURL, note string) er
 
 	log.Printf("Navigated to profile: %s", profileURL)
 
-	connectButton, err := cr.Page.Element(`button[aria-label^="Invite"]`)
+	// Record the profile details while we are here, for personalizing the
+	// note and follow-ups.
+	details, err := profile.Extract(cr.Page, profileURL)
 	if err != nil {
-		connectButton, err = cr.Page.Element(`button[data-control-name="connect"]`)
-		if err != nil {
-			return fmt.Errorf("connect button not found for %s: %w", profileURL, err)
+		log.Printf("Warning: Failed to scrape profile details of %s: %v", profileURL, err)
+	} else if err := cr.Storage.SaveProfileDetails(details); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Check the relationship first: the Connect button is missing for
+	// connections and pending invitations, and may hide under More.
+	state, err := DetectProfileState(cr.Page)
+	if err != nil {
+		return fmt.Errorf("failed to check relationship with %s: %w", profileURL, err)
+	}
+	log.Printf("Profile state of %s: %s", profileURL, state)
+	switch state {
+	case StateConnected, StatePending:
+		status := storage.StatusAlreadyConnected
+		if state == StatePending {
+			status = storage.StatusAlreadyPending
 		}
+		if err := cr.Storage.SaveSentRequest(&storage.SentRequest{ProfileURL: profileURL, SentAt: time.Now(), Status: status, Attempts: attempt}); err != nil {
+			return fmt.Errorf("failed to record profile state to database: %w", err)
+		}
+		log.Printf("Not sending a connection request to %s: %s", profileURL, state)
+		return nil
+	case StateFollowOnly:
+		return fmt.Errorf("cannot connect with %s: %w", profileURL, ErrFollowOnly)
+	case StateUnavailable:
+		return fmt.Errorf("cannot connect with %s: %w", profileURL, ErrProfileUnavailable)
+	}
+
+	// Render and validate the note before clicking anything.
+	noteText, err := renderNote(note, details, limits.NoteMaxLength, textlimit.Overflow(limits.NoteOverflow))
+	if err != nil {
+		return fmt.Errorf("cannot send a note to %s: %w", profileURL, err)
+	}
+
+	connectButton, err := findConnectButton(cr.Page, state)
+	if err != nil {
+		return fmt.Errorf("connect button not found for %s: %w", profileURL, err)
 	}
 
 	stealth.SimulateHumanClick(connectButton)
 	stealth.RandomDelay(1*time.Second, 2*time.Second) // Wait for modal to appear
 
+	// LinkedIn may show its weekly limit or ask for an email instead of the invitation modal
+	if err := cr.checkDialogs(profileURL); err != nil {
+		return err
+	}
+
 	addNoteButton, err := cr.Page.Element(`button.artdeco-button--secondary.mr1[aria-label="Add a note"]`)
-	if err == nil {
+	if noteText == "" {
+		sendButton := cr.Page.MustElement(`button[aria-label="Send without a note"], button[aria-label="Send now"]`)
+		stealth.SimulateHumanClick(sendButton)
+		stealth.RandomDelay(1*time.Second, 3*time.Second)
+	} else if err == nil {
 		stealth.SimulateHumanClick(addNoteButton)
 		stealth.RandomDelay(500*time.Millisecond, 1*time.Second) // Wait for textarea to appear
 
 		noteTextArea := cr.Page.MustElement(`textarea#custom-message`)
-		if len(note) > 300 {
-			note = note[:300]
-			log.Printf("Note truncated to 300 characters for %s", profileURL)
-		}
-		stealth.SimulateHumanTyping(noteTextArea, note)
+		stealth.SimulateHumanTyping(noteTextArea, noteText)
 		stealth.RandomDelay(1*time.Second, 3*time.Second)
 
 		sendButton := cr.Page.MustElement(`button[aria-label="Send now"]`)
@@ -89,22 +207,60 @@ func (cr *ConnectionRequester) SendConnectionRequest(profileURL, note string) er
 		stealth.RandomDelay(1*time.Second, 3*time.Second)
 	} else {
 		log.Println("No 'Add a note' option, sending direct connection request.")
+		noteText, note.TemplateID = "", ""
 		sendButton := cr.Page.MustElement(`button[aria-label="Send now"]`)
 		stealth.SimulateHumanClick(sendButton)
 		stealth.RandomDelay(1*time.Second, 3*time.Second)
 	}
 
+	// The weekly limit dialog can also replace the modal once Send is clicked
+	if err := cr.checkDialogs(profileURL); err != nil {
+		return err
+	}
+
+	// Only record the request once LinkedIn confirms it
+	confirmation, err := cr.verifyInvitation()
+	if err != nil {
+		return fmt.Errorf("connection request to %s: %w", profileURL, err)
+	}
+	log.Printf("Invitation to %s confirmed: %s", profileURL, confirmation)
+
 	// Save the sent request to storage
 	sentReq := &storage.SentRequest{
 		ProfileURL: profileURL,
-		Note:       note,
+		Note:       noteText,
+		TemplateID: note.TemplateID,
 		SentAt:     time.Now(),
 		Status:     storage.StatusSent,
+		Attempts:   attempt,
 	}
 	if err := cr.Storage.SaveSentRequest(sentReq); err != nil {
 		return fmt.Errorf("failed to save sent request to database: %w", err)
 	}
 
-	log.Printf("Connection request sent to %s with note: '%s'", profileURL, note)
+	log.Printf("Connection request sent to %s with note: '%s'", profileURL, noteText)
 	return nil
 }
+
+// renderNote fills the note template from its variables and the scraped
+// profile details, and checks the result can be sent. A note over maxLength
+// is rejected or truncated at a word boundary depending on overflow.
+func renderNote(note Note, details *storage.ProfileDetails, maxLength int, overflow textlimit.Overflow) (string, error) {
+	if strings.TrimSpace(note.Template) == "" {
+		return "", nil
+	}
+	variables := templates.Merge(note.Variables, profile.Variables(details), note.Overrides)
+	text, err := templates.RenderStrict(note.Template, variables)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidNote, err)
+	}
+	text = strings.TrimSpace(text)
+	limited, err := textlimit.Enforce(text, maxLength, overflow)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidNote, err)
+	}
+	if limited != text {
+		log.Printf("Connection note truncated from %d to %d characters", textlimit.Length(text), textlimit.Length(limited))
+	}
+	return limited, nil
+}
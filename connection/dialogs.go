@@ -0,0 +1,128 @@
+package connection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/go-rod/rod"
+	"linkedin-automation/stealth"
+	"linkedin-automation/storage"
+)
+
+var (
+	// ErrWeeklyLimit is returned when LinkedIn says the weekly invitation
+	// limit is reached, or while backing off after it did. No further
+	// invitations should be sent in this run.
+	ErrWeeklyLimit = errors.New("weekly invitation limit reached")
+	// ErrEmailRequired is returned when LinkedIn asks for the member's email
+	// address before it lets us connect.
+	ErrEmailRequired = errors.New("email address required to connect")
+)
+
+// rawDialog is what dialogsJS returns for each open dialog.
+type rawDialog struct {
+	Text       string `json:"text"`
+	EmailInput bool   `json:"emailInput"`
+}
+
+// dialogsJS reads the text of every visible modal dialog. Classification
+// happens in Go.
+const dialogsJS = `() => {
+	const dialogs = Array.from(document.querySelectorAll('[role="dialog"], [role="alertdialog"], .artdeco-modal'))
+		.filter(el => el.offsetParent !== null);
+	return JSON.stringify(dialogs.map(el => ({
+		text: el.innerText.trim(),
+		emailInput: !!el.querySelector('input[type="email"], input[name="email"]'),
+	})));
+}`
+
+var (
+	// weeklyLimitPattern matches "You've reached the weekly invitation limit"
+	// and "You're out of invitations for now".
+	weeklyLimitPattern = regexp.MustCompile(`(?i)weekly invitation limit|out of invitations|invitation limit`)
+	// emailRequiredPattern matches "To verify this member knows you, please
+	// enter their email to connect."
+	emailRequiredPattern = regexp.MustCompile(`(?i)enter (their|his|her) email|email (address )?to connect|verify this member knows you`)
+)
+
+// classifyDialog maps the text of a dialog to the restriction it shows, or
+// "" for ordinary dialogs such as the invitation modal itself.
+func classifyDialog(dialog rawDialog) storage.LimitEventKind {
+	switch {
+	case weeklyLimitPattern.MatchString(dialog.Text):
+		return storage.LimitEventWeeklyInvitations
+	case dialog.EmailInput || emailRequiredPattern.MatchString(dialog.Text):
+		return storage.LimitEventEmailRequired
+	default:
+		return ""
+	}
+}
+
+// checkDialogs looks for a restriction dialog on the current page. If one is
+// open it is recorded, dismissed, and returned as ErrWeeklyLimit or
+// ErrEmailRequired.
+func (cr *ConnectionRequester) checkDialogs(profileURL string) error {
+	obj, err := cr.Page.Eval(dialogsJS)
+	if err != nil {
+		return fmt.Errorf("failed to read dialogs: %w", err)
+	}
+	var dialogs []rawDialog
+	if err := json.Unmarshal([]byte(obj.Value.Str()), &dialogs); err != nil {
+		return fmt.Errorf("failed to decode dialogs: %w", err)
+	}
+
+	for _, dialog := range dialogs {
+		kind := classifyDialog(dialog)
+		if kind == "" {
+			continue
+		}
+		event := &storage.LimitEvent{
+			Kind:       kind,
+			ProfileURL: profileURL,
+			Message:    dialog.Text,
+			OccurredAt: time.Now(),
+		}
+		if err := cr.Storage.SaveLimitEvent(event); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		dismissDialog(cr.Page)
+
+		if kind == storage.LimitEventWeeklyInvitations {
+			return fmt.Errorf("invitation to %s not sent: %w", profileURL, ErrWeeklyLimit)
+		}
+		return fmt.Errorf("cannot connect with %s: %w", profileURL, ErrEmailRequired)
+	}
+	return nil
+}
+
+// dismissDialog closes the open dialog, if it has a close button.
+func dismissDialog(page *rod.Page) {
+	buttons, err := page.Elements(`[role="dialog"] button[aria-label="Dismiss"], [role="alertdialog"] button[aria-label="Dismiss"], .artdeco-modal__dismiss`)
+	if err != nil || len(buttons) == 0 {
+		log.Println("Warning: no button found to dismiss the dialog")
+		return
+	}
+	stealth.SimulateHumanClick(buttons[0])
+	stealth.RandomDelay(500*time.Millisecond, 1*time.Second) // Wait for the dialog to close
+}
+
+// checkBackoff returns ErrWeeklyLimit while backing off after LinkedIn last
+// showed its weekly invitation limit. A zero backoff disables the check.
+func (cr *ConnectionRequester) checkBackoff(backoff time.Duration) error {
+	if backoff <= 0 {
+		return nil
+	}
+	event, err := cr.Storage.GetLatestLimitEvent(storage.LimitEventWeeklyInvitations, time.Now().Add(-backoff))
+	if err != nil {
+		return fmt.Errorf("failed to check invitation limit events: %w", err)
+	}
+	if event != nil {
+		until := event.OccurredAt.Add(backoff).Local()
+		return fmt.Errorf("%w at %s, backing off until %s", ErrWeeklyLimit, event.OccurredAt.Local().Format(time.RFC1123), until.Format(time.RFC1123))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package connection
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"linkedin-automation/config"    // Import config for the note limits
+	"linkedin-automation/profile"   // Import profile to read profile details
+	"linkedin-automation/stealth"   // Import stealth for human-like browsing
+	"linkedin-automation/storage"   // Import storage for the profile details type
+	"linkedin-automation/textlimit" // Import textlimit to enforce the note length
+)
+
+// InspectProfile visits a profile and reads its relationship state and
+// details without clicking anything or writing to storage, e.g. for a dry
+// run. Transient browser failures are retried according to Retry.
+func (cr *ConnectionRequester) InspectProfile(profileURL string) (ProfileState, *storage.ProfileDetails, error) {
+	if cr.Browser == nil {
+		return "", nil, fmt.Errorf("browser not launched")
+	}
+	var state ProfileState
+	var details *storage.ProfileDetails
+	_, err := cr.Retry.Do("Inspecting "+profileURL, func(attempt int) error {
+		var err error
+		state, details, err = cr.inspectProfile(profileURL)
+		return err
+	})
+	return state, details, err
+}
+
+// inspectProfile makes one attempt at reading a profile's state and details.
+func (cr *ConnectionRequester) inspectProfile(profileURL string) (state ProfileState, details *storage.ProfileDetails, err error) {
+	if err := cr.Budget.SpendProfileVisit(); err != nil {
+		return "", nil, err
+	}
+	cr.Page = nil
+	defer func() {
+		// Rod's Must helpers panic; turn that into an error so it can be retried
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = panicErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	page := cr.Browser.MustPage("")
+	defer page.Close() // Retries and later profiles open their own tab
+	// Bound the attempt, so a profile that never finishes loading times out and is retried
+	var cancel func()
+	cr.Page, cancel = cr.Retry.WithTimeout(page)
+	defer cancel()
+	cr.Page.MustNavigate(profileURL).MustWaitLoad()
+	if err := stealth.ApplyPageStealth(cr.Page); err != nil {
+		log.Printf("Warning: Failed to apply stealth to profile page: %v", err)
+	}
+	stealth.RandomDelay(2*time.Second, 5*time.Second) // Simulate reading profile
+
+	details, err = profile.Extract(cr.Page, profileURL)
+	if err != nil {
+		log.Printf("Warning: Failed to scrape profile details of %s: %v", profileURL, err)
+		details = nil
+	}
+	state, err = DetectProfileState(cr.Page)
+	if err != nil {
+		return "", details, fmt.Errorf("failed to check relationship with %s: %w", profileURL, err)
+	}
+	return state, details, nil
+}
+
+// RenderNote renders a connection note the way SendConnectionRequest would
+// for a profile with the given details (nil if unknown), enforcing the note
+// limits. It returns an error wrapping ErrInvalidNote if the note could not
+// be sent.
+func RenderNote(note Note, details *storage.ProfileDetails, limits config.LimitsConfig) (string, error) {
+	return renderNote(note, details, limits.NoteMaxLength, textlimit.Overflow(limits.NoteOverflow))
+}
@@ -0,0 +1,191 @@
+package connection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"linkedin-automation/stealth"
+)
+
+// ProfileState is our relationship with a member as shown on their profile.
+type ProfileState string
+
+const (
+	StateConnected        ProfileState = "connected"
+	StatePending          ProfileState = "pending"     // An invitation is already waiting
+	StateConnectable      ProfileState = "connectable" // Connect is a top-card button
+	StateConnectUnderMore ProfileState = "connect-under-more-menu"
+	StateFollowOnly       ProfileState = "follow-only"
+	StateUnavailable      ProfileState = "unavailable" // Missing, private or restricted profile
+)
+
+var (
+	// ErrFollowOnly is returned for profiles that offer Follow but no way to connect.
+	ErrFollowOnly = errors.New("profile only offers follow")
+	// ErrProfileUnavailable is returned for profiles that do not exist or cannot be viewed.
+	ErrProfileUnavailable = errors.New("profile unavailable")
+)
+
+// rawProfileState is what profileStateJS returns.
+type rawProfileState struct {
+	HasName     bool     `json:"hasName"`
+	Unavailable bool     `json:"unavailable"`
+	Degree      string   `json:"degree"`
+	Actions     []string `json:"actions"`   // Visible top-card buttons
+	MenuItems   []string `json:"menuItems"` // Items of the More menu, once opened
+	HasMore     bool     `json:"hasMore"`
+}
+
+// topCardSelector matches the profile's top card, which holds the name and
+// the action buttons.
+const topCardSelector = `main section.artdeco-card`
+
+// moreButtonSelector matches the top card's More actions button.
+const moreButtonSelector = topCardSelector + ` button[aria-label="More actions"]`
+
+// moreMenuItemSelector matches the items of the opened More menu.
+const moreMenuItemSelector = topCardSelector + ` .artdeco-dropdown__content [role="button"]`
+
+// profileStateJS reads the labels of the top card's buttons and More menu
+// items. Each label combines aria-label and text, e.g. "Invite Jane Doe to
+// connect | Connect". Classification happens in Go.
+const profileStateJS = `(cardSelector) => {
+	const card = document.querySelector(cardSelector);
+	const label = el => ((el.getAttribute('aria-label') || '') + ' | ' + el.innerText).trim();
+	const bodyText = document.body ? document.body.innerText : '';
+	if (!card) {
+		return JSON.stringify({hasName: false, unavailable: true, actions: [], menuItems: []});
+	}
+	const buttons = Array.from(card.querySelectorAll('button, a[role="button"]'))
+		.filter(el => !el.closest('.artdeco-dropdown__content') && el.offsetParent !== null);
+	const menuItems = Array.from(card.querySelectorAll('.artdeco-dropdown__content [role="button"]'));
+	const degree = card.querySelector('.dist-value');
+	return JSON.stringify({
+		hasName: !!card.querySelector('h1'),
+		unavailable: /this page doesn.t exist|profile is not available|this profile is restricted/i.test(bodyText),
+		degree: degree ? degree.innerText.trim() : '',
+		actions: buttons.map(label),
+		menuItems: menuItems.map(label),
+		hasMore: !!card.querySelector('button[aria-label="More actions"]'),
+	});
+}`
+
+// DetectProfileState works out our relationship with the member whose profile
+// is loaded in page. When the top card offers no Connect button it opens the
+// More menu to look there; the menu is left open for the caller.
+func DetectProfileState(page *rod.Page) (ProfileState, error) {
+	raw, err := readProfileState(page)
+	if err != nil {
+		return "", err
+	}
+	state := classifyProfileState(raw)
+	if state != StateFollowOnly || !raw.HasMore {
+		return state, nil
+	}
+
+	moreButton, err := page.Element(moreButtonSelector)
+	if err != nil {
+		return state, nil
+	}
+	stealth.SimulateHumanClick(moreButton)
+	stealth.RandomDelay(500*time.Millisecond, 1*time.Second) // Wait for the menu to open
+
+	raw, err = readProfileState(page)
+	if err != nil {
+		return "", err
+	}
+	return classifyProfileState(raw), nil
+}
+
+// readProfileState evaluates profileStateJS on page.
+func readProfileState(page *rod.Page) (rawProfileState, error) {
+	var raw rawProfileState
+	obj, err := page.Eval(profileStateJS, topCardSelector)
+	if err != nil {
+		return raw, fmt.Errorf("failed to read profile state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(obj.Value.Str()), &raw); err != nil {
+		return raw, fmt.Errorf("failed to decode profile state: %w", err)
+	}
+	return raw, nil
+}
+
+// classifyProfileState maps the button labels of a profile to a ProfileState.
+// An invitation already waiting wins over everything else, since LinkedIn
+// keeps showing other actions next to Pending.
+func classifyProfileState(raw rawProfileState) ProfileState {
+	switch {
+	case raw.Unavailable || !raw.HasName:
+		return StateUnavailable
+	case anyLabel(raw.Actions, isPendingLabel) || anyLabel(raw.MenuItems, isPendingLabel):
+		return StatePending
+	case firstDegreePattern.MatchString(raw.Degree) || anyLabel(raw.MenuItems, isRemoveConnectionLabel):
+		return StateConnected
+	case anyLabel(raw.Actions, isConnectLabel):
+		return StateConnectable
+	case anyLabel(raw.MenuItems, isConnectLabel):
+		return StateConnectUnderMore
+	default:
+		return StateFollowOnly
+	}
+}
+
+// firstDegreePattern matches the "1st" degree badge.
+var firstDegreePattern = regexp.MustCompile(`\b1st\b`)
+
+// isConnectLabel matches "Invite Jane Doe to connect | Connect".
+func isConnectLabel(label string) bool {
+	label = strings.ToLower(label)
+	return strings.Contains(label, "to connect") || strings.HasSuffix(label, "| connect")
+}
+
+// isPendingLabel matches "Pending, click to withdraw invitation sent to Jane Doe | Pending".
+func isPendingLabel(label string) bool {
+	label = strings.ToLower(label)
+	return strings.HasPrefix(label, "pending") || strings.HasSuffix(label, "| pending") || strings.Contains(label, "withdraw invitation")
+}
+
+// isRemoveConnectionLabel matches the "Remove connection" item only shown for connections.
+func isRemoveConnectionLabel(label string) bool {
+	return strings.Contains(strings.ToLower(label), "remove connection")
+}
+
+// anyLabel reports whether any label satisfies match.
+func anyLabel(labels []string, match func(string) bool) bool {
+	for _, label := range labels {
+		if match(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// findConnectButton returns the Connect button for state, which is either a
+// top-card button or an item of the (already opened) More menu.
+func findConnectButton(page *rod.Page, state ProfileState) (*rod.Element, error) {
+	if state == StateConnectUnderMore {
+		items, err := page.Elements(moreMenuItemSelector)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if isConnectLabel(elementLabel(item)) {
+				log.Println("Connect is under the More menu.")
+				return item, nil
+			}
+		}
+		return nil, fmt.Errorf("connect item not found in the More menu")
+	}
+
+	connectButton, err := page.Element(topCardSelector + ` button[aria-label^="Invite"]`)
+	if err != nil {
+		connectButton, err = page.Element(`button[data-control-name="connect"]`)
+	}
+	return connectButton, err
+}
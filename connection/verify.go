@@ -0,0 +1,114 @@
+package connection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotConfirmed is returned when LinkedIn did not confirm that an
+// invitation was sent. Nothing is recorded as sent, so the profile is tried
+// again later; if the invitation did go out, the relationship check then
+// finds it pending.
+var ErrNotConfirmed = errors.New("invitation not confirmed")
+
+// verifyTimeout is how long to wait for LinkedIn to confirm an invitation.
+const verifyTimeout = 10 * time.Second
+
+// rawConfirmation is what confirmationJS returns.
+type rawConfirmation struct {
+	Toasts    []string `json:"toasts"`
+	ModalOpen bool     `json:"modalOpen"`
+}
+
+// confirmationJS reads the toast notifications and whether the invitation
+// modal is still open.
+const confirmationJS = `() => {
+	const toasts = Array.from(document.querySelectorAll('.artdeco-toast-item, [role="alert"]'))
+		.map(el => el.innerText.trim())
+		.filter(text => text !== '');
+	const modal = document.querySelector('.send-invite, [role="dialog"] textarea#custom-message, [role="dialog"] button[aria-label="Send now"]');
+	return JSON.stringify({toasts: toasts, modalOpen: !!(modal && modal.offsetParent !== null)});
+}`
+
+var (
+	// sentToastPattern matches the whole of "Invitation sent" and "Your
+	// invitation to Jane Doe was sent.", so a failure that mentions the
+	// invitation is not taken for a confirmation.
+	sentToastPattern = regexp.MustCompile(`(?i)^(your )?invitation (to .+ )?(was )?sent\.?$`)
+	// failedToastPattern matches toasts saying the invitation did not go out.
+	failedToastPattern = regexp.MustCompile(`(?i)something went wrong|not sent|n.t be sent|not be sent|unable to send|couldn.t send|try again`)
+)
+
+// toastOutcome is what a toast says about an invitation.
+type toastOutcome int
+
+const (
+	toastUnrelated toastOutcome = iota // Says nothing about the invitation
+	toastSent
+	toastFailed
+)
+
+// classifyToast reads a toast notification. Failures are checked first, as
+// they often mention the invitation too ("Your invitation to Jane Doe was not
+// sent"). Only the first line counts for success, since a toast's text may
+// end with the label of its dismiss button.
+func classifyToast(toast string) toastOutcome {
+	if failedToastPattern.MatchString(toast) {
+		return toastFailed
+	}
+	firstLine := strings.TrimSpace(strings.SplitN(strings.TrimSpace(toast), "\n", 2)[0])
+	if sentToastPattern.MatchString(firstLine) {
+		return toastSent
+	}
+	return toastUnrelated
+}
+
+// verifyInvitation waits until LinkedIn confirms the invitation, either with
+// a toast or by showing Pending on the profile, and returns how it was
+// confirmed. It fails with ErrNotConfirmed and the reason otherwise.
+func (cr *ConnectionRequester) verifyInvitation() (string, error) {
+	reason := "no confirmation"
+	deadline := time.Now().Add(verifyTimeout)
+	for {
+		obj, err := cr.Page.Eval(confirmationJS)
+		if err != nil {
+			return "", fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		var confirmation rawConfirmation
+		if err := json.Unmarshal([]byte(obj.Value.Str()), &confirmation); err != nil {
+			return "", fmt.Errorf("failed to decode confirmation: %w", err)
+		}
+
+		for _, toast := range confirmation.Toasts {
+			switch classifyToast(toast) {
+			case toastFailed:
+				return "", fmt.Errorf("%w: LinkedIn said %q", ErrNotConfirmed, toast)
+			case toastSent:
+				return "toast: " + toast, nil
+			}
+		}
+
+		if confirmation.ModalOpen {
+			reason = "the invitation modal is still open"
+		} else {
+			raw, err := readProfileState(cr.Page)
+			if err != nil {
+				return "", err
+			}
+			state := classifyProfileState(raw)
+			if state == StatePending {
+				return "the profile shows Pending", nil
+			}
+			reason = fmt.Sprintf("the profile shows %s instead of pending", state)
+		}
+
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("%w within %s: %s", ErrNotConfirmed, verifyTimeout, reason)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -0,0 +1,32 @@
+package connection
+
+import "testing"
+
+func TestClassifyToast(t *testing.T) {
+	tests := []struct {
+		toast string
+		want  toastOutcome
+	}{
+		{"Invitation sent", toastSent},
+		{"Invitation sent.", toastSent},
+		{"Your invitation to Jane Doe was sent.", toastSent},
+		{"Your invitation to Jane Doe was sent.\nDismiss", toastSent},
+		{"  your invitation to 李小龍 was sent  ", toastSent},
+		{"Your invitation to Jane Doe was not sent", toastFailed},
+		{"Invitation not sent", toastFailed},
+		{"Invitation couldn't be sent", toastFailed},
+		{"Invitation couldn’t be sent. Please try again.", toastFailed},
+		{"Your invitation could not be sent", toastFailed},
+		{"Unable to send invitation", toastFailed},
+		{"Something went wrong. Try again later.", toastFailed},
+		{"Invitations sent this week: 80", toastUnrelated},
+		{"Jane Doe accepted your invitation", toastUnrelated},
+		{"Profile viewed", toastUnrelated},
+		{"", toastUnrelated},
+	}
+	for _, tt := range tests {
+		if got := classifyToast(tt.toast); got != tt.want {
+			t.Errorf("classifyToast(%q) = %d, want %d", tt.toast, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"linkedin-automation/stealth"
+	"linkedin-automation/storage"
+)
+
+// ErrNotPending is returned when asked to withdraw an invitation that the
+// profile does not show as pending, e.g. because it was already accepted.
+var ErrNotPending = errors.New("no pending invitation")
+
+// WithdrawInvitation withdraws the pending invitation to a profile and marks
+// the sent request as withdrawn. Transient browser failures are retried
+// according to Retry.
+func (cr *ConnectionRequester) WithdrawInvitation(profileURL string) error {
+	if cr.Browser == nil {
+		return fmt.Errorf("browser not launched")
+	}
+	_, err := cr.Retry.Do("Withdrawing the invitation to "+profileURL, func(attempt int) error {
+		return cr.withdrawInvitation(profileURL, attempt)
+	})
+	return err
+}
+
+// withdrawInvitation makes one attempt (starting at 1) at withdrawing the
+// invitation to a profile.
+func (cr *ConnectionRequester) withdrawInvitation(profileURL string, attempt int) (err error) {
+	if err := cr.Budget.SpendProfileVisit(); err != nil {
+		return err
+	}
+	cr.Page = nil
+	var page *rod.Page // The tab without the attempt's deadline, still usable for evidence after a timeout
+	defer func() {
+		// Rod's Must helpers panic; turn that into an error so it can be retried
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = panicErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+		// Keep evidence of whatever went wrong on the profile page
+		if err != nil && page != nil {
+			cr.Artifacts.Capture(page, storage.ActionWithdraw, profileURL, attempt, err)
+		}
+		// Close the tab, so retries and later actions do not pile up open tabs
+		if page != nil {
+			page.Close()
+		}
+	}()
+	page = cr.Browser.MustPage("")
+	var cancel func()
+	cr.Page, cancel = cr.Retry.WithTimeout(page)
+	defer cancel()
+	cr.Page.MustNavigate(profileURL).MustWaitLoad()
+	if err := stealth.ApplyPageStealth(cr.Page); err != nil {
+		log.Printf("Warning: Failed to apply stealth to withdraw page: %v", err)
+	}
+	stealth.RandomDelay(2*time.Second, 5*time.Second) // Simulate reading profile
+
+	state, err := DetectProfileState(cr.Page)
+	if err != nil {
+		return fmt.Errorf("failed to check relationship with %s: %w", profileURL, err)
+	}
+	if state == StateConnected {
+		// The invitation was accepted meanwhile, which makes it due a follow-up
+		if _, err := cr.recordAccepted(profileURL); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	if state != StatePending {
+		return fmt.Errorf("cannot withdraw from %s, the profile is %s: %w", profileURL, state, ErrNotPending)
+	}
+
+	pendingButton, err := findPendingButton(cr.Page)
+	if err != nil {
+		return fmt.Errorf("pending button not found for %s: %w", profileURL, err)
+	}
+	stealth.SimulateHumanClick(pendingButton)
+	stealth.RandomDelay(1*time.Second, 2*time.Second) // Wait for the confirmation dialog
+
+	withdrawButton, err := findDialogButton(cr.Page, "withdraw")
+	if err != nil {
+		return fmt.Errorf("withdraw confirmation not found for %s: %w", profileURL, err)
+	}
+	stealth.SimulateHumanClick(withdrawButton)
+	stealth.RandomDelay(1*time.Second, 3*time.Second)
+
+	// Only record the withdrawal once the profile no longer shows Pending
+	deadline := time.Now().Add(verifyTimeout)
+	for {
+		raw, err := readProfileState(cr.Page)
+		if err != nil {
+			return err
+		}
+		if state = classifyProfileState(raw); state != StatePending {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("invitation to %s still pending %s after withdrawing", profileURL, verifyTimeout)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	existingRequest, err := cr.Storage.GetSentRequestByProfileURL(profileURL)
+	if err != nil {
+		return fmt.Errorf("failed to check existing request: %w", err)
+	}
+	if existingRequest != nil {
+		if err := cr.Storage.UpdateRequestStatus(profileURL, storage.StatusWithdrawn); err != nil {
+			return err
+		}
+	}
+	log.Printf("Invitation to %s withdrawn (profile now %s)", profileURL, state)
+	return nil
+}
+
+// recordAccepted marks the invitation this tool sent to a profile that is now
+// a connection as accepted and reports whether there was one. Requests in any
+// other status are left alone.
+func (cr *ConnectionRequester) recordAccepted(profileURL string) (bool, error) {
+	existingRequest, err := cr.Storage.GetSentRequestByProfileURL(profileURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to check existing request: %w", err)
+	}
+	if existingRequest == nil || existingRequest.Status != storage.StatusSent {
+		return false, nil
+	}
+	log.Printf("Invitation to %s was accepted", profileURL)
+	return true, cr.Storage.UpdateRequestStatus(profileURL, storage.StatusAccepted)
+}
+
+// findPendingButton returns the Pending button of the top card, or the
+// Pending item of the (already opened) More menu.
+func findPendingButton(page *rod.Page) (*rod.Element, error) {
+	for _, selector := range []string{topCardSelector + ` button`, moreMenuItemSelector} {
+		elements, err := page.Elements(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range elements {
+			if isPendingLabel(elementLabel(element)) {
+				return element, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no pending button on the profile")
+}
+
+// findDialogButton returns the button of the open dialog whose text is label,
+// compared case-insensitively.
+func findDialogButton(page *rod.Page, label string) (*rod.Element, error) {
+	buttons, err := page.Elements(`[role="dialog"] button, [role="alertdialog"] button`)
+	if err != nil {
+		return nil, err
+	}
+	for _, button := range buttons {
+		text, _ := button.Text()
+		if strings.EqualFold(strings.TrimSpace(text), label) {
+			return button, nil
+		}
+	}
+	return nil, fmt.Errorf("no %q button in the dialog", label)
+}
+
+// elementLabel combines an element's aria-label and text the way
+// profileStateJS does, e.g. "Invite Jane Doe to connect | Connect".
+func elementLabel(element *rod.Element) string {
+	text, _ := element.Text()
+	ariaLabel, _ := element.Attribute("aria-label")
+	if ariaLabel == nil {
+		return text
+	}
+	return *ariaLabel + " | " + text
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"linkedin-automation/budget"
+	"linkedin-automation/config"
+	"linkedin-automation/connection"
+	"linkedin-automation/dryrun" // Import dryrun for the report of planned actions
+	"linkedin-automation/exclusion"
+	"linkedin-automation/messaging"
+	"linkedin-automation/profile"
+	"linkedin-automation/schedule"
+	"linkedin-automation/scoring"
+	"linkedin-automation/search"
+	"linkedin-automation/storage"
+	"linkedin-automation/templates"
+	"linkedin-automation/textlimit"
+)
+
+// dryRunPlan is an action a run would take and the profile it is for.
+type dryRunPlan struct {
+	action storage.QueuedAction
+	target storage.CampaignProfile
+}
+
+// runDryRun works out every action a run would take, in the order it would
+// take them, renders their notes and messages and writes them to a report,
+// without sending anything or changing the queues. With visit, each profile
+// is opened (read-only) to check the relationship and personalize from its
+// details.
+func runDryRun(cfg *config.Config, store *storage.Storage, campaign string, results []search.SearchResult,
+	connRequester *connection.ConnectionRequester, schedules *schedule.Source, visit bool, reportPath string) error {
+	now := time.Now()
+	report := &dryrun.Report{GeneratedAt: now, Campaign: campaign}
+
+	// Actions already in the queue run first, in the order they are due.
+	pending, err := store.ListActions(storage.ActionPending)
+	if err != nil {
+		return err
+	}
+	var plans []dryRunPlan
+	planned := make(map[string]bool)
+	for _, action := range pending {
+		target, err := actionTarget(store, &action)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, dryRunPlan{action: action, target: *target})
+		planned[string(action.Kind)+" "+action.ProfileURL] = true
+	}
+
+	// Then an invitation for every candidate that passes the exclusion rules,
+	// best lead score first. Profiles found by the search are considered
+	// without queueing them.
+	candidates, err := store.GetQueuedCampaignProfiles(campaign)
+	if err != nil {
+		return fmt.Errorf("failed to load the queue of campaign %q: %w", campaign, err)
+	}
+	for _, found := range searchProfiles(campaign, results, now) {
+		existing, err := store.GetCampaignProfile(campaign, found.ProfileURL)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			found.Status = storage.CampaignProfileQueued
+			candidates = append(candidates, found)
+		}
+	}
+	filter := exclusion.NewFilter(cfg.Campaign.Exclude, store)
+	scorer := scoring.NewScorer(cfg.Campaign.Scoring)
+	var ranked []storage.CampaignProfile
+	for _, candidate := range candidates {
+		rule, err := filter.Check(candidate)
+		if err != nil {
+			return err
+		}
+		if rule != "" {
+			report.Actions = append(report.Actions, dryrun.PlannedAction{
+				Kind: storage.ActionInvite, Campaign: campaign, ProfileURL: candidate.ProfileURL, Name: candidate.Name,
+				Status: dryrun.StatusExcluded, Reason: rule,
+			})
+			continue
+		}
+		candidate.Score, _ = scorer.Score(candidate)
+		ranked = append(ranked, candidate)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	for _, target := range ranked {
+		if !planned[string(storage.ActionInvite)+" "+target.ProfileURL] {
+			action := storage.QueuedAction{Campaign: campaign, Kind: storage.ActionInvite, ProfileURL: target.ProfileURL, ScheduledAt: now}
+			plans = append(plans, dryRunPlan{action: action, target: target})
+		}
+	}
+
+	// And a follow-up for every accepted connection not messaged yet.
+	accepted, err := store.GetProfilesWithAcceptedRequestsWithoutMessage()
+	if err != nil {
+		return err
+	}
+	for _, profileURL := range accepted {
+		if planned[string(storage.ActionMessage)+" "+profileURL] {
+			continue
+		}
+		action := storage.QueuedAction{Campaign: campaign, Kind: storage.ActionMessage, ProfileURL: profileURL, ScheduledAt: now}
+		target, err := actionTarget(store, &action)
+		if err != nil {
+			return err
+		}
+		plans = append(plans, dryRunPlan{action: action, target: *target})
+	}
+
+	// Actions only run inside the working hours and up to the daily limits.
+	start := now
+	if next, ok := schedules.Current().Next(now); ok {
+		start = next
+	}
+	left := make(map[storage.ActionKind]int)
+	if sent, err := store.GetCountOfSentRequestsToday(); err == nil {
+		left[storage.ActionInvite] = cfg.Limits.DailyConnections - sent
+	}
+	if sent, err := store.GetCountOfMessagesToday(); err == nil {
+		left[storage.ActionMessage] = cfg.Limits.DailyMessages - sent
+	}
+
+	for _, plan := range plans {
+		row := previewAction(cfg, store, connRequester, plan, &visit)
+		scheduledAt := plan.action.ScheduledAt
+		if scheduledAt.Before(start) {
+			scheduledAt = start
+		}
+		row.ScheduledAt = &scheduledAt
+		if row.Status == dryrun.StatusPlanned && plan.action.ScheduledAt.After(now) {
+			row.Status = dryrun.StatusNotDue
+		}
+		if count, ok := left[row.Kind]; ok && row.Status == dryrun.StatusPlanned {
+			if count <= 0 {
+				row.Status, row.Reason = dryrun.StatusOverLimit, "daily limit reached"
+			}
+			left[row.Kind] = count - 1
+		}
+		log.Printf("Dry run: %s %s for %s %s", row.Status, row.Kind, row.ProfileURL, row.Reason)
+		if row.Text != "" {
+			log.Printf("Dry run: %s text for %s (%d characters): %s", row.Kind, row.ProfileURL, row.Length, row.Text)
+		}
+		report.Actions = append(report.Actions, row)
+	}
+
+	if err := report.Write(reportPath); err != nil {
+		return err
+	}
+	counts := report.Counts()
+	log.Printf("Dry run: %d actions (%d planned, %d over the daily limit, %d not due, %d skipped, %d excluded, %d invalid, %d not visited); report written to %s",
+		len(report.Actions), counts[dryrun.StatusPlanned], counts[dryrun.StatusOverLimit], counts[dryrun.StatusNotDue], counts[dryrun.StatusSkipped],
+		counts[dryrun.StatusExcluded], counts[dryrun.StatusInvalid], counts[dryrun.StatusNotVisited], reportPath)
+	return nil
+}
+
+// previewAction works out what executing a planned action would do, short
+// of clicking anything. visit is cleared once the profile visit budget is
+// spent, so later actions are previewed from stored details.
+func previewAction(cfg *config.Config, store *storage.Storage, connRequester *connection.ConnectionRequester, plan dryRunPlan, visit *bool) dryrun.PlannedAction {
+	action, target := plan.action, plan.target
+	row := dryrun.PlannedAction{
+		Kind:       action.Kind,
+		Campaign:   action.Campaign,
+		ProfileURL: action.ProfileURL,
+		Name:       target.Name,
+		Score:      target.Score,
+		Status:     dryrun.StatusPlanned,
+	}
+
+	// What a real run would skip without visiting the profile
+	if action.ID == 0 {
+		// Not queued yet, so it would only be planned if it never failed
+		if failed, err := store.HasFailedAction(action.Kind, action.ProfileURL); err == nil && failed {
+			row.Status, row.Reason = dryrun.StatusSkipped, fmt.Sprintf("an earlier %s action failed", action.Kind)
+			return row
+		}
+	}
+	switch action.Kind {
+	case storage.ActionInvite:
+		if target.Status != storage.CampaignProfileQueued {
+			row.Status, row.Reason = dryrun.StatusSkipped, fmt.Sprintf("profile is %s in the campaign", target.Status)
+			return row
+		}
+		if sent, err := store.GetSentRequestByProfileURL(action.ProfileURL); err == nil && sent != nil {
+			row.Status, row.Reason = dryrun.StatusSkipped, fmt.Sprintf("request already processed (status: %s)", sent.Status)
+			return row
+		}
+	case storage.ActionMessage:
+		if sent, err := store.GetMessageRecord(action.ProfileURL); err == nil && sent != nil {
+			row.Status, row.Reason = dryrun.StatusSkipped, "follow-up message already sent"
+			return row
+		}
+	}
+
+	details, err := store.GetProfileDetails(action.ProfileURL)
+	if err != nil {
+		log.Printf("Failed to load profile details of %s: %v", action.ProfileURL, err)
+	}
+	if *visit {
+		state, visited, err := connRequester.InspectProfile(action.ProfileURL)
+		if errors.Is(err, budget.ErrExhausted) {
+			log.Printf("Dry run: no longer visiting profiles: %v", err)
+			*visit = false
+		}
+		if err != nil {
+			row.Status, row.Reason = dryrun.StatusNotVisited, err.Error()
+			return row
+		}
+		row.ProfileState = string(state)
+		if visited != nil {
+			details = visited
+		}
+		if reason := stateSkipReason(action.Kind, state); reason != "" {
+			row.Status, row.Reason = dryrun.StatusSkipped, reason
+			return row
+		}
+	}
+
+	variables, overrides := targetVariables(cfg, target)
+	switch action.Kind {
+	case storage.ActionInvite:
+		template, overrides := payloadTemplate(&action, cfg.Templates.ConnectionNote, overrides)
+		note := connection.Note{
+			TemplateID: templates.ID("connection_note", template),
+			Template:   template,
+			Variables:  variables,
+			Overrides:  overrides,
+		}
+		row.TemplateID = note.TemplateID
+		row.Text, err = connection.RenderNote(note, details, cfg.Limits)
+	case storage.ActionMessage:
+		template, overrides := payloadTemplate(&action, cfg.Templates.FollowUp, overrides)
+		row.TemplateID = templates.ID("follow_up", template)
+		row.Text, err = messaging.RenderMessage(template, templates.Merge(variables, profile.Variables(details), overrides), cfg.Limits)
+	}
+	if err != nil {
+		row.Status, row.Reason = dryrun.StatusInvalid, err.Error()
+	}
+	row.Length = textlimit.Length(row.Text)
+	return row
+}
+
+// stateSkipReason explains why an action would be skipped for a profile in
+// the given relationship state, or returns "" if it would go ahead.
+func stateSkipReason(kind storage.ActionKind, state connection.ProfileState) string {
+	switch kind {
+	case storage.ActionInvite:
+		if state != connection.StateConnectable && state != connection.StateConnectUnderMore {
+			return fmt.Sprintf("profile is %s", state)
+		}
+	case storage.ActionWithdraw:
+		if state != connection.StatePending {
+			return fmt.Sprintf("no pending invitation, the profile is %s", state)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package dryrun
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"linkedin-automation/storage" // Import storage for the kinds of action
+)
+
+// Status says what a run would do with a planned action.
+type Status string
+
+const (
+	StatusPlanned    Status = "planned"     // Would be executed
+	StatusOverLimit  Status = "over_limit"  // Would wait for a later day because of the daily limit
+	StatusNotDue     Status = "not_due"     // Scheduled for later
+	StatusSkipped    Status = "skipped"     // Would be skipped, e.g. already connected
+	StatusExcluded   Status = "excluded"    // Ruled out by the exclusion rules
+	StatusInvalid    Status = "invalid"     // The note or message could not be sent as rendered
+	StatusNotVisited Status = "not_visited" // The profile could not be inspected
+)
+
+// PlannedAction is one row of a dry-run report.
+type PlannedAction struct {
+	Kind         storage.ActionKind `json:"kind"`
+	Campaign     string             `json:"campaign"`
+	ProfileURL   string             `json:"profile_url"`
+	Name         string             `json:"name,omitempty"`
+	Score        float64            `json:"score"`
+	ScheduledAt  *time.Time         `json:"scheduled_at,omitempty"` // Earliest time it would run, within the working hours
+	Status       Status             `json:"status"`
+	Reason       string             `json:"reason,omitempty"`
+	ProfileState string             `json:"profile_state,omitempty"` // Only known if profiles were visited
+	TemplateID   string             `json:"template_id,omitempty"`
+	Text         string             `json:"text,omitempty"`   // The rendered note or message
+	Length       int                `json:"length,omitempty"` // Length of Text as the site counts it
+}
+
+// Report is the outcome of a dry run.
+type Report struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Campaign    string          `json:"campaign"`
+	Actions     []PlannedAction `json:"actions"`
+}
+
+// Counts returns the number of actions per status.
+func (r *Report) Counts() map[Status]int {
+	counts := make(map[Status]int)
+	for _, action := range r.Actions {
+		counts[action.Status]++
+	}
+	return counts
+}
+
+// Write saves the report to path, as JSON if it ends in ".json" and as CSV
+// otherwise.
+func (r *Report) Write(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(r)
+	} else {
+		err = r.writeCSV(file)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	return nil
+}
+
+// csvHeader names the columns written by writeCSV.
+var csvHeader = []string{"kind", "campaign", "profile_url", "name", "score", "scheduled_at", "status", "reason", "profile_state", "template_id", "length", "text"}
+
+// writeCSV writes one row per action.
+func (r *Report) writeCSV(file *os.File) error {
+	writer := csv.NewWriter(file)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, action := range r.Actions {
+		scheduledAt := ""
+		if action.ScheduledAt != nil {
+			scheduledAt = action.ScheduledAt.Format(time.RFC3339)
+		}
+		record := []string{
+			string(action.Kind),
+			action.Campaign,
+			action.ProfileURL,
+			action.Name,
+			strconv.FormatFloat(action.Score, 'g', -1, 64),
+			scheduledAt,
+			string(action.Status),
+			action.Reason,
+			action.ProfileState,
+			action.TemplateID,
+			strconv.Itoa(action.Length),
+			action.Text,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,110 @@
+package exclusion
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"linkedin-automation/config"
+	"linkedin-automation/storage"
+)
+
+// Rule names recorded for excluded profiles. Rules with a configured value
+// are recorded as "<rule>:<value>", e.g. "headline_keyword:recruiter".
+const (
+	RuleFirstDegree      = "first_degree"
+	RuleAlreadyContacted = "already_contacted"
+	RuleCompany          = "company"
+	RuleHeadlineKeyword  = "headline_keyword"
+	RuleLocation         = "location"
+)
+
+// Filter drops profiles matching a campaign's exclusion rules before any
+// outreach happens.
+type Filter struct {
+	Rules   config.ExclusionConfig
+	Storage *storage.Storage // Used to check for earlier invitations
+
+	keywordPatterns map[string]*regexp.Regexp
+}
+
+// NewFilter creates a Filter for the given rules.
+func NewFilter(rules config.ExclusionConfig, store *storage.Storage) *Filter {
+	f := &Filter{Rules: rules, Storage: store, keywordPatterns: make(map[string]*regexp.Regexp)}
+	for _, keyword := range rules.HeadlineKeywords {
+		if strings.TrimSpace(keyword) == "" {
+			continue
+		}
+		// Whole words only, so "intern" does not match "international".
+		f.keywordPatterns[keyword] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(strings.TrimSpace(keyword)) + `\b`)
+	}
+	return f
+}
+
+// Check returns the rule that excludes profile, or "" if it may be contacted.
+func (f *Filter) Check(profile storage.CampaignProfile) (string, error) {
+	if f.Rules.FirstDegree && profile.ConnectionDegree == 1 {
+		return RuleFirstDegree, nil
+	}
+
+	if f.Rules.AlreadyContacted && f.Storage != nil {
+		sent, err := f.Storage.GetSentRequestByProfileURL(profile.ProfileURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to check for an earlier invitation: %w", err)
+		}
+		if sent != nil {
+			return RuleAlreadyContacted, nil
+		}
+	}
+
+	if company := profile.CurrentCompany(); company != "" {
+		for _, excluded := range f.Rules.Companies {
+			if containsFold(company, excluded) {
+				return RuleCompany + ":" + excluded, nil
+			}
+		}
+	}
+
+	for _, keyword := range f.Rules.HeadlineKeywords {
+		if pattern, ok := f.keywordPatterns[keyword]; ok && pattern.MatchString(profile.Headline) {
+			return RuleHeadlineKeyword + ":" + keyword, nil
+		}
+	}
+
+	for _, excluded := range f.Rules.Locations {
+		if containsFold(profile.Location, excluded) {
+			return RuleLocation + ":" + excluded, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Apply checks every profile, records the excluded ones in storage with the
+// rule that matched, and returns the profiles that remain.
+func (f *Filter) Apply(campaign string, profiles []storage.CampaignProfile) ([]storage.CampaignProfile, error) {
+	var kept []storage.CampaignProfile
+	for _, profile := range profiles {
+		rule, err := f.Check(profile)
+		if err != nil {
+			return nil, err
+		}
+		if rule == "" {
+			kept = append(kept, profile)
+			continue
+		}
+		if err := f.Storage.ExcludeCampaignProfile(campaign, profile.ProfileURL, rule); err != nil {
+			return nil, err
+		}
+		log.Printf("Excluded %s (%s): %s", profile.ProfileURL, profile.Name, rule)
+	}
+	return kept, nil
+}
+
+// containsFold reports whether substr is within s, ignoring case and
+// surrounding whitespace. An empty substr never matches.
+func containsFold(s, substr string) bool {
+	substr = strings.ToLower(strings.TrimSpace(substr))
+	return substr != "" && strings.Contains(strings.ToLower(s), substr)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"linkedin-automation/config"
+)
+
+// overrideFlag implements -set key=value, overriding a config key above the
+// environment and every config file.
+type overrideFlag struct {
+	loader *config.Loader
+}
+
+func (f overrideFlag) String() string {
+	return ""
+}
+
+func (f overrideFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f.loader.Set(key, val)
+	return nil
+}
@@ -0,0 +1,194 @@
+package holidays
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Holiday is a span of whole days off, e.g. one event of an iCal calendar.
+type Holiday struct {
+	Name   string
+	Start  time.Time // First day off, as a date at midnight UTC
+	End    time.Time // First day back at work, as a date at midnight UTC
+	Yearly bool      // Repeats on the same dates every year
+}
+
+// Calendar is a list of holidays.
+type Calendar struct {
+	Holidays []Holiday
+}
+
+// Load reads the holidays from an iCal (.ics) file. Times in the file
+// without a timezone of their own are read in loc.
+func Load(path string, loc *time.Location) (*Calendar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holidays file: %w", err)
+	}
+	defer file.Close()
+	calendar, err := Parse(file, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holidays file %s: %w", path, err)
+	}
+	return calendar, nil
+}
+
+// Parse reads the VEVENTs of an iCal calendar as holidays. Only what a
+// holiday calendar needs is supported: DTSTART and DTEND as dates or times
+// (an event covers every day it touches), SUMMARY, and yearly repetition.
+func Parse(r io.Reader, loc *time.Location) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{}
+	var event *Holiday
+	var endSet bool
+	for i, line := range lines {
+		name, params, value := splitProperty(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			event, endSet = &Holiday{}, false
+		case event == nil:
+			// Properties of the calendar itself or of other components
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", i+1, event.Name)
+			}
+			if !endSet || !event.End.After(event.Start) {
+				event.End = event.Start.AddDate(0, 0, 1)
+			}
+			calendar.Holidays = append(calendar.Holidays, *event)
+			event = nil
+		case name == "SUMMARY":
+			event.Name = unescape(value)
+		case name == "DTSTART":
+			start, _, err := parseDate(value, params, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			event.Start = start
+		case name == "DTEND":
+			end, hasTime, err := parseDate(value, params, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			// An event ending during a day still takes that day off
+			if hasTime {
+				end = end.AddDate(0, 0, 1)
+			}
+			event.End, endSet = end, true
+		case name == "RRULE":
+			if !strings.Contains(strings.ToUpper(value), "FREQ=YEARLY") {
+				return nil, fmt.Errorf("line %d: only yearly repeating events are supported, got RRULE:%s", i+1, value)
+			}
+			event.Yearly = true
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("event %q is not closed with END:VEVENT", event.Name)
+	}
+	return calendar, nil
+}
+
+// On returns the holiday covering the date of t, or nil if t is a working day.
+func (c *Calendar) On(t time.Time) *Holiday {
+	if c == nil {
+		return nil
+	}
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for i := range c.Holidays {
+		holiday := &c.Holidays[i]
+		if !day.Before(holiday.Start) && day.Before(holiday.End) {
+			return holiday
+		}
+		if !holiday.Yearly || day.Before(holiday.Start) {
+			continue
+		}
+		// Move the event to this year, and to last year for one spanning New Year
+		for _, year := range []int{day.Year(), day.Year() - 1} {
+			shift := year - holiday.Start.Year()
+			start, end := holiday.Start.AddDate(shift, 0, 0), holiday.End.AddDate(shift, 0, 0)
+			if !day.Before(start) && day.Before(end) {
+				return holiday
+			}
+		}
+	}
+	return nil
+}
+
+// unfold joins the continuation lines of an iCal file, which start with a
+// space or tab, to the line before them.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// splitProperty splits "DTSTART;VALUE=DATE:20261225" into the upper-case
+// name, its parameters and the value.
+func splitProperty(line string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return strings.ToUpper(strings.TrimSpace(parts[0])), params, strings.TrimSpace(value)
+}
+
+// parseDate parses a DATE ("20261225") or DATE-TIME ("20261225T090000Z", or
+// local to TZID or loc) value and returns its date, in loc for times, as
+// midnight UTC. It also reports whether the value had a time of day.
+func parseDate(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if len(value) == len("20060102") {
+		date, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return date, false, nil
+	}
+
+	var t time.Time
+	var err error
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse("20060102T150405Z", value)
+	case params["TZID"] != "":
+		var tz *time.Location
+		if tz, err = time.LoadLocation(params["TZID"]); err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID %q", params["TZID"])
+		}
+		t, err = time.ParseInLocation("20060102T150405", value, tz)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	t = t.In(loc)
+	midnight := t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), !midnight, nil
+}
+
+// unescape undoes the escaping of iCal text values.
+func unescape(text string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(text)
+}
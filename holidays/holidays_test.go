@@ -0,0 +1,132 @@
+package holidays
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available:", err)
+	}
+	tests := []struct {
+		name  string
+		event string
+		want  Holiday
+	}{
+		{
+			name:  "date-only DTEND is the first day back",
+			event: "SUMMARY:Christmas\nDTSTART;VALUE=DATE:20261224\nDTEND;VALUE=DATE:20261227",
+			want:  Holiday{Name: "Christmas", Start: date(2026, 12, 24), End: date(2026, 12, 27)},
+		},
+		{
+			name:  "no DTEND is a single day",
+			event: "SUMMARY:Unity Day\nDTSTART;VALUE=DATE:20261003",
+			want:  Holiday{Name: "Unity Day", Start: date(2026, 10, 3), End: date(2026, 10, 4)},
+		},
+		{
+			name:  "time-of-day DTEND takes that day off too",
+			event: "SUMMARY:Offsite\nDTSTART:20261104T090000\nDTEND:20261105T130000",
+			want:  Holiday{Name: "Offsite", Start: date(2026, 11, 4), End: date(2026, 11, 6)},
+		},
+		{
+			name:  "DTEND at midnight is the first day back",
+			event: "SUMMARY:Offsite\nDTSTART:20261104T000000\nDTEND:20261106T000000",
+			want:  Holiday{Name: "Offsite", Start: date(2026, 11, 4), End: date(2026, 11, 6)},
+		},
+		{
+			name:  "UTC times are read in the account's timezone",
+			event: "SUMMARY:Late\nDTSTART:20261231T230000Z\nDTEND:20261231T233000Z",
+			want:  Holiday{Name: "Late", Start: date(2027, 1, 1), End: date(2027, 1, 2)},
+		},
+		{
+			name:  "TZID wins over the account's timezone",
+			event: "SUMMARY:Call\nDTSTART;TZID=America/New_York:20261231T200000\nDTEND;TZID=America/New_York:20261231T210000",
+			want:  Holiday{Name: "Call", Start: date(2027, 1, 1), End: date(2027, 1, 2)},
+		},
+		{
+			name:  "yearly event with an escaped, folded summary",
+			event: "SUMMARY:New Year\\, \n observed\nDTSTART;VALUE=DATE:20261231\nDTEND;VALUE=DATE:20270102\nRRULE:FREQ=YEARLY",
+			want:  Holiday{Name: "New Year, observed", Start: date(2026, 12, 31), End: date(2027, 1, 2), Yearly: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ics := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + strings.ReplaceAll(tt.event, "\n", "\r\n") + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+			calendar, err := Parse(strings.NewReader(ics), berlin)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(calendar.Holidays) != 1 {
+				t.Fatalf("got %d holidays, want 1", len(calendar.Holidays))
+			}
+			if got := calendar.Holidays[0]; got != tt.want {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ics  string
+	}{
+		{"no DTSTART", "BEGIN:VEVENT\nSUMMARY:Nothing\nEND:VEVENT\n"},
+		{"invalid date", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261332\nEND:VEVENT\n"},
+		{"unknown TZID", "BEGIN:VEVENT\nDTSTART;TZID=Mars/Olympus:20261224T090000\nEND:VEVENT\n"},
+		{"weekly repetition", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261224\nRRULE:FREQ=WEEKLY\nEND:VEVENT\n"},
+		{"unclosed event", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261224\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(strings.NewReader(tt.ics), time.UTC); err == nil {
+			t.Errorf("%s: want an error", tt.name)
+		}
+	}
+}
+
+func TestOn(t *testing.T) {
+	calendar := &Calendar{Holidays: []Holiday{
+		{Name: "Christmas", Start: date(2026, 12, 24), End: date(2026, 12, 27)},
+		{Name: "New Year", Start: date(2025, 12, 31), End: date(2026, 1, 2), Yearly: true},
+		{Name: "Labour Day", Start: date(2026, 5, 1), End: date(2026, 5, 2), Yearly: true},
+	}}
+	tests := []struct {
+		day  time.Time
+		want string // Name of the holiday, or "" for a working day
+	}{
+		{date(2026, 12, 23), ""},
+		{date(2026, 12, 24), "Christmas"},
+		{time.Date(2026, 12, 26, 23, 59, 0, 0, time.UTC), "Christmas"},
+		{date(2026, 12, 27), ""},         // DTEND is the first day back
+		{date(2027, 12, 24), ""},         // Not yearly
+		{date(2025, 12, 30), ""},         // Before the first occurrence
+		{date(2025, 12, 31), "New Year"}, // First occurrence
+		{date(2027, 1, 1), "New Year"},   // The event of 2026 spanning New Year
+		{date(2027, 12, 31), "New Year"}, // Moved to this year
+		{date(2028, 1, 2), ""},           // Day after the event of 2027
+		{date(2025, 5, 1), ""},           // Yearly, but before the first occurrence
+		{date(2030, 5, 1), "Labour Day"}, // Moved to a later year
+		{time.Date(2030, 5, 1, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), "Labour Day"}, // The date of t counts, not UTC
+	}
+	for _, tt := range tests {
+		got := ""
+		if holiday := calendar.On(tt.day); holiday != nil {
+			got = holiday.Name
+		}
+		if got != tt.want {
+			t.Errorf("On(%s) = %q, want %q", tt.day.Format("2006-01-02 15:04 MST"), got, tt.want)
+		}
+	}
+
+	var none *Calendar
+	if none.On(date(2026, 12, 24)) != nil {
+		t.Error("nil calendar: want no holiday")
+	}
+}
@@ -0,0 +1,197 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"linkedin-automation/search" // For profile URL normalization
+)
+
+// Profile is one entry of an imported target list.
+type Profile struct {
+	ProfileURL string // Normalized profile URL
+	Name       string
+	Company    string
+	Headline   string
+	Location   string
+	// Variables holds every other column, for use as template variables.
+	Variables map[string]string
+}
+
+// Result is the outcome of reading a target list.
+type Result struct {
+	Profiles []Profile
+	// Skipped describes each entry that was dropped and why, e.g. an invalid
+	// profile URL or a duplicate of an earlier entry.
+	Skipped []string
+}
+
+// urlColumns are the accepted names of the profile URL column.
+var urlColumns = []string{"profile_url", "url", "linkedin_url", "linkedin", "profile"}
+
+// ReadFile reads a target list from a .csv or .json file.
+func ReadFile(path string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReadCSV(f)
+	case ".json":
+		return ReadJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported import file %s: expected .csv or .json", path)
+	}
+}
+
+// ReadCSV reads a CSV target list. The first row is a header naming the
+// columns: a profile URL column (profile_url, url, linkedin_url, ...) is
+// required, name, company, headline and location are optional, and any other column becomes a
+// template variable named after its header.
+func ReadCSV(r io.Reader) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Exports often drop trailing empty columns
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")) // Excel adds a byte order mark
+	}
+	if findColumn(header, urlColumns) < 0 {
+		return nil, fmt.Errorf("CSV header has no profile URL column (expected one of: %s)", strings.Join(urlColumns, ", "))
+	}
+
+	var entries []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		entry := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) && header[i] != "" {
+				entry[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	// Line numbers count the header, matching what a spreadsheet shows.
+	return buildResult(entries, 2), nil
+}
+
+// ReadJSON reads a JSON target list: an array of objects with a profile URL
+// field, optional name, company, headline and location, and either a "variables" object or any
+// other string fields as template variables.
+func ReadJSON(r io.Reader) (*Result, error) {
+	var raw []map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON import: %w", err)
+	}
+
+	entries := make([]map[string]string, 0, len(raw))
+	for _, object := range raw {
+		entry := make(map[string]string, len(object))
+		for key, value := range object {
+			switch value := value.(type) {
+			case string:
+				entry[key] = strings.TrimSpace(value)
+			case float64, bool:
+				entry[key] = fmt.Sprint(value)
+			case map[string]interface{}:
+				if key != "variables" {
+					continue
+				}
+				for name, v := range value {
+					entry[name] = strings.TrimSpace(fmt.Sprint(v))
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return buildResult(entries, 1), nil
+}
+
+// buildResult normalizes entries into Profiles, dropping those without a
+// valid profile URL and later duplicates of the same profile. firstLine is
+// the line or item number of the first entry, used in Skipped messages.
+func buildResult(entries []map[string]string, firstLine int) *Result {
+	result := &Result{}
+	seen := make(map[string]int)
+	for i, entry := range entries {
+		line := firstLine + i
+
+		var rawURL string
+		profile := Profile{Variables: make(map[string]string)}
+		for key, value := range entry {
+			switch normalized := strings.ToLower(key); {
+			case containsString(urlColumns, normalized):
+				if rawURL == "" {
+					rawURL = value
+				}
+			case normalized == "name":
+				profile.Name = value
+			case normalized == "company":
+				profile.Company = value
+			case normalized == "headline":
+				profile.Headline = value
+			case normalized == "location":
+				profile.Location = value
+			case value != "":
+				profile.Variables[key] = value
+			}
+		}
+
+		if rawURL == "" {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("entry %d: no profile URL", line))
+			continue
+		}
+		profileURL, ok := search.NormalizeProfileURL(rawURL)
+		if !ok {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("entry %d: not a LinkedIn profile URL: %s", line, rawURL))
+			continue
+		}
+		if first, ok := seen[profileURL]; ok {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("entry %d: duplicate of entry %d (%s)", line, first, profileURL))
+			continue
+		}
+		seen[profileURL] = line
+
+		profile.ProfileURL = profileURL
+		result.Profiles = append(result.Profiles, profile)
+	}
+	return result
+}
+
+// findColumn returns the index of the first header matching one of names, or -1.
+func findColumn(header []string, names []string) int {
+	for i, column := range header {
+		if containsString(names, strings.ToLower(column)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
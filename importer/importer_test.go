@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := "\ufeffLinkedIn_URL, Name ,Company,Interest\n" +
+		"https://www.linkedin.com/in/Jane-Doe?trk=public_profile,Jane Doe,Acme,distributed systems\n" +
+		"linkedin.com/in/ravi-kumar,Ravi Kumar,,\n" +
+		"https://www.linkedin.com/in/jane-doe/,Jane D.,Acme\n" +
+		"https://www.linkedin.com/company/acme/,Acme,Acme,\n" +
+		",No URL,,\n"
+	result, err := ReadCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantProfiles := []Profile{
+		{ProfileURL: "https://www.linkedin.com/in/jane-doe/", Name: "Jane Doe", Company: "Acme", Variables: map[string]string{"Interest": "distributed systems"}},
+		{ProfileURL: "https://www.linkedin.com/in/ravi-kumar/", Name: "Ravi Kumar", Variables: map[string]string{}},
+	}
+	if !reflect.DeepEqual(result.Profiles, wantProfiles) {
+		t.Errorf("profiles:\n got %+v\nwant %+v", result.Profiles, wantProfiles)
+	}
+	wantSkipped := []string{
+		"entry 4: duplicate of entry 2 (https://www.linkedin.com/in/jane-doe/)",
+		"entry 5: not a LinkedIn profile URL: https://www.linkedin.com/company/acme/",
+		"entry 6: no profile URL",
+	}
+	if !reflect.DeepEqual(result.Skipped, wantSkipped) {
+		t.Errorf("skipped:\n got %q\nwant %q", result.Skipped, wantSkipped)
+	}
+}
+
+func TestReadCSVURLColumnAliases(t *testing.T) {
+	for _, column := range urlColumns {
+		result, err := ReadCSV(strings.NewReader(strings.ToUpper(column) + "\nhttps://www.linkedin.com/in/jane-doe\n"))
+		if err != nil {
+			t.Errorf("column %q: %v", column, err)
+			continue
+		}
+		if len(result.Profiles) != 1 || result.Profiles[0].ProfileURL != "https://www.linkedin.com/in/jane-doe/" {
+			t.Errorf("column %q: got %+v", column, result.Profiles)
+		}
+	}
+
+	if _, err := ReadCSV(strings.NewReader("name,company\nJane Doe,Acme\n")); err == nil {
+		t.Error("header without a profile URL column: want an error")
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `[
+		{"url": "https://www.linkedin.com/in/jane-doe", "name": "Jane Doe", "company": "Acme",
+		 "variables": {"Interest": " distributed systems ", "Years": 5}},
+		{"profile_url": "https://www.linkedin.com/in/ravi-kumar/", "Name": "Ravi Kumar", "Team": "Platform", "remote": true,
+		 "ignored": {"nested": "object"}},
+		{"url": "https://evil-linkedin.com/in/jane-doe"},
+		{"url": "https://de.linkedin.com/in/Jane-Doe/"}
+	]`
+	result, err := ReadJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantProfiles := []Profile{
+		{ProfileURL: "https://www.linkedin.com/in/jane-doe/", Name: "Jane Doe", Company: "Acme", Variables: map[string]string{"Interest": "distributed systems", "Years": "5"}},
+		{ProfileURL: "https://www.linkedin.com/in/ravi-kumar/", Name: "Ravi Kumar", Variables: map[string]string{"Team": "Platform", "remote": "true"}},
+	}
+	if !reflect.DeepEqual(result.Profiles, wantProfiles) {
+		t.Errorf("profiles:\n got %+v\nwant %+v", result.Profiles, wantProfiles)
+	}
+	wantSkipped := []string{
+		"entry 3: not a LinkedIn profile URL: https://evil-linkedin.com/in/jane-doe",
+		"entry 4: duplicate of entry 1 (https://www.linkedin.com/in/jane-doe/)",
+	}
+	if !reflect.DeepEqual(result.Skipped, wantSkipped) {
+		t.Errorf("skipped:\n got %q\nwant %q", result.Skipped, wantSkipped)
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"url": "https://www.linkedin.com/in/jane-doe"}`)); err == nil {
+		t.Error("JSON object instead of an array: want an error")
+	}
+}
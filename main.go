@@ -1,35 +1,120 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"linkedin-automation/actions"
+	"linkedin-automation/artifacts"
 	"linkedin-automation/authentication"
+	"linkedin-automation/budget"
 	"linkedin-automation/config"
 	"linkedin-automation/connection"
+	"linkedin-automation/exclusion"
 	"linkedin-automation/messaging"
+	"linkedin-automation/profile"
+	"linkedin-automation/retry"
+	"linkedin-automation/schedule"
+	"linkedin-automation/scoring"
 	"linkedin-automation/search"
-	"linkedin-automation/stealth"
 	"linkedin-automation/storage" // Import the storage package
+	"linkedin-automation/templates"
 )
 
 func main() {
-	cfg, err := config.LoadConfig()
+	loader := config.NewLoader()
+	flag.StringVar(&loader.ConfigFile, "config", "", "base config file (default: config.yaml in . or ./config)")
+	flag.StringVar(&loader.Account, "account", "", "named account profile from the accounts section of the config")
+	accountConfig := flag.String("account-config", "", "config file merged over the base config")
+	campaignConfig := flag.String("campaign", "", "campaign config file merged over the base and account configs")
+	savedSearch := flag.String("search", "", "run the named saved search (see the searches command) instead of the search criteria in the config")
+	rerun := flag.Bool("rerun", false, "with -search, only surface profiles not found by earlier runs of the saved search, stopping at the first page with nothing new")
+	skipSearch := flag.Bool("skip-search", false, "do not search; only contact profiles already queued for the campaign (e.g. by the import command)")
+	dryRun := flag.Bool("dry-run", false, "search, filter, score and render every note and message, but send nothing and change no queues; write the planned actions to -dry-run-report")
+	dryRunReport := flag.String("dry-run-report", "dry-run-report.csv", "with -dry-run, the report of planned actions; written as JSON if it ends in .json, as CSV otherwise")
+	dryRunVisit := flag.Bool("dry-run-visit", false, "with -dry-run, open each profile (read-only) to check the relationship and personalize from its details")
+	flag.Var(overrideFlag{loader}, "set", "override a config key, e.g. -set limits.daily_connections=20 (repeatable)")
+	flag.Parse()
+	for _, path := range []string{*accountConfig, *campaignConfig} {
+		if path != "" {
+			loader.OverlayFiles = append(loader.OverlayFiles, path)
+		}
+	}
+
+	daemon := false
+	if args := flag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "run":
+			runFlags := flag.NewFlagSet("run", flag.ExitOnError)
+			runFlags.BoolVar(&daemon, "daemon", false, "keep running, executing queued actions inside the working windows until interrupted")
+			runFlags.Parse(args[1:])
+		case "config":
+			os.Exit(runConfigCommand(loader, args[1:]))
+		case "searches":
+			os.Exit(runSearchesCommand(loader, args[1:]))
+		case "import":
+			os.Exit(runImportCommand(loader, args[1:]))
+		case "budget":
+			os.Exit(runBudgetCommand(loader, args[1:]))
+		case "actions":
+			os.Exit(runActionsCommand(loader, args[1:]))
+		default:
+			log.Fatalf("Unknown command %q (expected run, config, searches, import, budget or actions)", args[0])
+		}
+	}
+
+	if *dryRun && daemon {
+		log.Fatalf("-dry-run cannot be combined with run -daemon")
+	}
+
+	cfg, err := loader.Load()
 	if err != nil {
 		log.Fatalf("Error loading configuration: %v", err)
 	}
 
+	logFile, err := setupLogging(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	if logFile != nil {
+		defer logFile.Close()
+	}
+
 	log.Printf("Configuration loaded successfully. LinkedIn Username: %s", cfg.LinkedIn.Username)
+	if cfg.Account != "" {
+		log.Printf("Using account profile %q (database: %s, session: %s)", cfg.Account, cfg.Storage.DBPath, cfg.Session.CookiePath)
+	}
+
+	// Watch the config files so limits, pacing, templates and the schedule
+	// can be changed while the tool is running.
+	live := config.NewLive(loader, cfg)
+	if stopWatching, err := live.Watch(); err != nil {
+		log.Printf("Warning: live config reload disabled: %v", err)
+	} else {
+		defer stopWatching()
+	}
 
 	// Initialize Storage
-	dbPath := "linkedin_automation.db" // Define your database path
-	store, err := storage.NewStorage(dbPath)
+	store, err := storage.NewStorage(cfg.Storage.DBPath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer store.Close() // Ensure database connection is closed
 
+	// Evidence of failed actions goes into a directory per run
+	startedAt := time.Now()
+	recorder := artifacts.NewRecorder(cfg.Storage.ArtifactsDir, startedAt, store)
+
 	auth := authentication.NewAuthenticator(cfg)
+	auth.Artifacts = recorder
 	if err := auth.LaunchBrowser(); err != nil {
 		log.Fatalf("Failed to launch browser: %v", err)
 	}
@@ -41,80 +126,450 @@ func main() {
 
 	log.Println("Successfully authenticated and logged in to LinkedIn.")
 
-	// Initialize Searcher
-	searcher := search.NewSearcher(auth.Browser) // Pass the authenticated browser instance
+	campaign := cfg.Campaign.Name
+	runBudget := budget.New(cfg.Budget.ResultPages, cfg.Budget.ProfileVisits)
+	defer func() {
+		if *dryRun {
+			// A dry run leaves no trace in the database, not even its budget
+			log.Printf("Dry run: campaign %q used %s", campaign, runBudget.Usage())
+			return
+		}
+		reportBudget(store, campaign, startedAt, runBudget)
+	}()
 
-	// Define sample search criteria
-	searchCriteria := search.SearchUserCriteria{
-		JobTitle:  "Software Engineer",
-		Location:  "San Francisco Bay Area",
-		Keywords:  []string{"Go", "Golang"},
-		PageLimit: 1, // Search across 1 page for demonstration
+	var results []search.SearchResult
+	if !*skipSearch {
+		results, err = runSearch(auth, cfg, store, runBudget, recorder, *savedSearch, *rerun, !*dryRun)
+		if err != nil {
+			log.Fatalf("Error during user search: %v", err)
+		}
+		if !*dryRun {
+			queueSearchResults(store, campaign, results)
+		}
 	}
 
-	log.Printf("Starting user search with criteria: %+v", searchCriteria)
-	profileURLs, err := searcher.SearchUsers(searchCriteria)
+	// Initialize ConnectionRequester with storage
+	connRequester := connection.NewConnectionRequester(auth.Browser, store, live)
+	connRequester.Budget = runBudget
+	connRequester.Artifacts = recorder
+	connRequester.Retry = retryPolicy(cfg)
+
+	// Initialize Messenger with storage
+	messenger := messaging.NewMessenger(auth.Browser, store, live)
+	messenger.Budget = runBudget
+	messenger.Artifacts = recorder
+	messenger.Retry = retryPolicy(cfg)
+	scraper := profile.NewScraper(auth.Browser, store)
+	scraper.Budget = runBudget
+
+	if *dryRun {
+		log.Println("Dry run: nothing will be sent.")
+		if err := runDryRun(live.Current(), store, campaign, results, connRequester, schedule.NewSource(live), *dryRunVisit, *dryRunReport); err != nil {
+			log.Fatalf("Dry run failed: %v", err)
+		}
+		return
+	}
+
+	// Actions left running by an interrupted run go back into the queue
+	if released, err := store.ReleaseRunningActions(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if released > 0 {
+		log.Printf("Re-queued %d actions interrupted by an earlier run", released)
+	}
+
+	// Stop waiting for working hours or between actions on Ctrl+C or SIGTERM
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	schedules := schedule.NewSource(live)
+
+	worker := newWorker(ctx, store, live, schedules, connRequester, messenger, scraper)
+
+	var checkedAccepted time.Time
+	for {
+		planInvitations(cfg, live, store, campaign)
+
+		// Send connection requests and whatever else is due
+		log.Println("Executing queued actions...")
+		runQueue(worker)
+
+		// Find out which invitations were accepted, at most once per interval
+		if time.Since(checkedAccepted) >= acceptanceCheckInterval {
+			if accepted, err := connRequester.DetectAcceptedInvitations(); err != nil {
+				log.Printf("Failed to check for accepted invitations: %v", err)
+			} else {
+				log.Printf("%d invitations accepted", accepted)
+			}
+			checkedAccepted = time.Now()
+		}
+
+		planFollowUps(store, campaign)
+		log.Println("Sending follow-up messages to accepted connections...")
+		runQueue(worker)
+
+		if !daemon || ctx.Err() != nil {
+			break
+		}
+		// Look for newly queued profiles and due actions again in a while
+		if err := sleep(ctx, daemonPollInterval); err != nil {
+			break
+		}
+		// Each day of a long-running process gets a fresh scrape budget
+		if now := time.Now(); now.YearDay() != startedAt.YearDay() || now.Year() != startedAt.Year() {
+			reportBudget(store, campaign, startedAt, runBudget)
+			runBudget.Reset()
+			startedAt = now
+		}
+	}
+
+	log.Println("Automation task completed.")
+}
+
+// daemonPollInterval is how long run -daemon waits between passes over the
+// campaign queue and the action queue.
+const daemonPollInterval = 5 * time.Minute
+
+// acceptanceCheckInterval is how often run -daemon opens the connections page
+// to look for accepted invitations.
+const acceptanceCheckInterval = time.Hour
+
+// planInvitations plans invitations for the profiles queued for the campaign
+// that pass the exclusion rules, best lead score first and only as many as
+// the day's remaining budget, so the queue sends them highest scored first.
+// Profiles already planned (e.g. by the actions command or an earlier run)
+// keep their slot.
+func planInvitations(cfg *config.Config, live *config.Live, store *storage.Storage, campaign string) {
+	queue, err := store.GetQueuedCampaignProfiles(campaign)
 	if err != nil {
-		log.Fatalf("Error during user search: %v", err)
+		log.Printf("Failed to load the queue of campaign %q: %v", campaign, err)
+		return
 	}
+	log.Printf("%d profiles queued for campaign %q", len(queue), campaign)
 
-	log.Printf("Found %d unique profile URLs:", len(profileURLs))
-	for _, url := range profileURLs {
-		log.Println(url)
+	// Drop anyone the campaign's exclusion rules rule out before reaching out.
+	queue, err = exclusion.NewFilter(cfg.Campaign.Exclude, store).Apply(campaign, queue)
+	if err != nil {
+		log.Printf("Failed to apply exclusion rules: %v", err)
+		return
 	}
+	log.Printf("%d profiles left after exclusion rules", len(queue))
 
-	// Initialize ConnectionRequester with storage
-	connRequester := connection.NewConnectionRequester(auth.Browser, store)
+	// Spend the daily budget on the best matches first.
+	queue, err = scoring.NewScorer(cfg.Campaign.Scoring).Rank(store, campaign, queue)
+	if err != nil {
+		log.Printf("Failed to score queued profiles: %v", err)
+		return
+	}
+	remaining, err := remainingInvitations(store, live)
+	if err != nil {
+		log.Printf("Failed to check the remaining invitations: %v", err)
+		return
+	}
 
-	// Send connection requests
-	log.Println("Sending connection requests...")
-	for i, profileURL := range profileURLs {
-		// Example personalized note
-		note := "Hi, I came across your profile and was impressed by your work in Go. I'd love to connect!"
-		if err := connRequester.SendConnectionRequest(profileURL, note); err != nil {
-			log.Printf("Failed to send connection request to %s: %v", profileURL, err)
+	planned := 0
+	for _, target := range queue {
+		if planned >= remaining {
+			break
 		}
-		// Add a longer delay between connection requests to avoid rate limits and detection
-		if i < len(profileURLs)-1 {
-			stealth.RandomDelay(5*time.Second, 15*time.Second) // Human-like delay between requests
+		added, err := planAction(store, &storage.QueuedAction{Campaign: campaign, Kind: storage.ActionInvite, ProfileURL: target.ProfileURL})
+		if err != nil {
+			log.Printf("Failed to plan an invitation to %s: %v", target.ProfileURL, err)
+		} else if added {
+			planned++
 		}
 	}
+	log.Printf("Planned %d new invitations", planned)
+}
 
-	// Initialize Messenger with storage
-	messenger := messaging.NewMessenger(auth.Browser, store)
-
-	// Simulate accepted connections for demonstration purposes
-	// In a real scenario, you would use messenger.DetectNewConnections()
-	// and then filter for profiles where a connection request was sent by this tool.
-	simulatedAcceptedConnections := []string{}
-	// Use the first found profile as a simulated accepted connection for demonstration
-	// In a real scenario, you would get this from DB after a successful connection.
-	if len(profileURLs) > 0 {
-		// For demonstration, let's assume the first profile request was accepted
-		// Update status in DB
-		err := store.UpdateRequestStatus(profileURLs[0], storage.StatusAccepted)
+// planFollowUps plans a follow-up message for every accepted connection not
+// messaged yet.
+func planFollowUps(store *storage.Storage, campaign string) {
+	accepted, err := store.GetProfilesWithAcceptedRequestsWithoutMessage()
+	if err != nil {
+		log.Printf("Failed to load accepted connections: %v", err)
+		return
+	}
+	for _, profileURL := range accepted {
+		if _, err := planAction(store, &storage.QueuedAction{Campaign: campaign, Kind: storage.ActionMessage, ProfileURL: profileURL}); err != nil {
+			log.Printf("Failed to plan a follow-up message to %s: %v", profileURL, err)
+		}
+	}
+}
+
+// runQueue executes the due actions and logs how they ended.
+func runQueue(worker *actions.Worker) {
+	summary, err := worker.Run()
+	if errors.Is(err, context.Canceled) {
+		log.Println("Interrupted, the remaining actions stay queued.")
+	} else if err != nil {
+		log.Printf("Action queue stopped: %v", err)
+	}
+	log.Printf("Action queue: %s", summary)
+}
+
+// remainingInvitations returns how many more invitations can be planned
+// today: the daily limit minus the invitations sent today and those already
+// pending.
+func remainingInvitations(store *storage.Storage, live *config.Live) (int, error) {
+	sent, err := store.GetCountOfSentRequestsToday()
+	if err != nil {
+		return 0, err
+	}
+	pending, err := store.CountPendingActions(storage.ActionInvite)
+	if err != nil {
+		return 0, err
+	}
+	return live.Limits().DailyConnections - sent - pending, nil
+}
+
+// planAction queues an action unless the profile already has one of its kind
+// pending or an earlier one failed for good. Only the actions command plans
+// a failed action again.
+func planAction(store *storage.Storage, action *storage.QueuedAction) (bool, error) {
+	failed, err := store.HasFailedAction(action.Kind, action.ProfileURL)
+	if err != nil || failed {
+		return false, err
+	}
+	return store.EnqueueAction(action)
+}
+
+// targetVariables returns the template variables for a queued profile: the
+// configured variables, then the campaign's, then what the queue knows about
+// the person. The overrides, from the columns of an imported list, are set
+// deliberately and so win over details scraped from the profile as well.
+func targetVariables(cfg *config.Config, target storage.CampaignProfile) (variables, overrides map[string]string) {
+	known := map[string]string{
+		"Name": "Connection Name", // Used when nothing better is known
+	}
+	if target.Name != "" {
+		known["Name"] = target.Name
+	}
+	if target.Company != "" {
+		known["Company"] = target.Company
+	}
+	if target.Headline != "" {
+		known["Headline"] = target.Headline
+	}
+	if target.Location != "" {
+		known["Location"] = target.Location
+	}
+	return templates.Merge(cfg.Templates.Variables, cfg.Campaign.Variables, known), target.Variables
+}
+
+// profileStateRule names the profile state behind a connection error, for
+// recording why a profile left the campaign queue.
+func profileStateRule(err error) string {
+	if errors.Is(err, connection.ErrFollowOnly) {
+		return string(connection.StateFollowOnly)
+	}
+	if errors.Is(err, connection.ErrEmailRequired) {
+		return "email-required"
+	}
+	return string(connection.StateUnavailable)
+}
+
+// retryPolicy returns the policy for retrying transient browser failures.
+func retryPolicy(cfg *config.Config) retry.Policy {
+	return retry.Policy{
+		Attempts:       cfg.Retry.Attempts,
+		InitialDelay:   cfg.Retry.InitialDelay,
+		MaxDelay:       cfg.Retry.MaxDelay,
+		AttemptTimeout: cfg.Retry.AttemptTimeout,
+	}
+}
+
+// reportBudget logs how much of its budget the run used and records it for
+// the budget command.
+func reportBudget(store *storage.Storage, campaign string, startedAt time.Time, runBudget *budget.Budget) {
+	usage := runBudget.Usage()
+	log.Printf("Campaign %q used %s", campaign, usage)
+	err := store.SaveRunBudget(&storage.RunBudget{
+		Campaign:           campaign,
+		StartedAt:          startedAt,
+		ResultPages:        usage.ResultPages,
+		ResultPagesLimit:   usage.ResultPagesLimit,
+		CachedPages:        usage.CachedPages,
+		ProfileVisits:      usage.ProfileVisits,
+		ProfileVisitsLimit: usage.ProfileVisitsLimit,
+	})
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// waitUntilActive blocks until the schedule allows outreach, re-reading the
+// live config so edits take effect without a restart. It returns ctx's error
+// if ctx is done first.
+func waitUntilActive(ctx context.Context, schedules *schedule.Source) error {
+	logged := false
+	for {
+		scheduler := schedules.Current()
+		now := time.Now()
+		if scheduler.Active(now) {
+			return nil
+		}
+		if !logged {
+			if next, ok := scheduler.Next(now); ok {
+				log.Printf("Waiting: %s; the next working window starts %s", scheduler.Reason(now), next.Format(time.RFC1123))
+			} else {
+				log.Printf("Waiting: %s", scheduler.Reason(now))
+			}
+			logged = true
+		}
+		if err := sleep(ctx, time.Minute); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d, or returns ctx's error if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// setupLogging applies the logging config. The returned file, if any, must be
+// closed by the caller.
+func setupLogging(cfg config.LoggingConfig) (*os.File, error) {
+	if cfg.Level == "debug" {
+		log.SetFlags(log.LstdFlags | log.Lshortfile)
+	}
+	if cfg.File == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	log.SetOutput(io.MultiWriter(os.Stderr, f))
+	return f, nil
+}
+
+// runSearch runs the named saved search, or the search criteria from the
+// config when savedSearch is empty, and returns the profiles found. With
+// rerun, profiles found by earlier runs of the saved search are left out.
+// Result pages come from the search cache when possible and are charged to
+// runBudget otherwise. Pages that fail to load are captured by recorder.
+// Unless recordRun, the run of a saved search is not recorded, e.g. for a dry
+// run.
+func runSearch(auth *authentication.Authenticator, cfg *config.Config, store *storage.Storage, runBudget *budget.Budget, recorder *artifacts.Recorder, savedSearch string, rerun, recordRun bool) ([]search.SearchResult, error) {
+	// Initialize Searcher
+	searcher := search.NewSearcher(auth.Browser) // Pass the authenticated browser instance
+	searcher.Storage = store
+	searcher.CacheTTL = cfg.Search.CacheTTL
+	searcher.Budget = runBudget
+	searcher.Artifacts = recorder
+	searcher.Retry = retryPolicy(cfg)
+	if err := searcher.Facets.LoadFile(cfg.Search.FacetTableFile); err != nil {
+		return nil, fmt.Errorf("failed to load search facet table: %w", err)
+	}
+	searcher.Facets.CacheFile = cfg.Search.FacetTableFile // IDs looked up during the run are kept for the next one
+
+	// Search criteria come from the search defaults in the config
+	searchCriteria := search.SearchUserCriteria{
+		JobTitle:         cfg.Search.JobTitle,
+		Keywords:         cfg.Search.Keywords,
+		CurrentCompanies: cfg.Search.CurrentCompanies,
+		PastCompanies:    cfg.Search.PastCompanies,
+		Locations:        cfg.Search.Locations,
+		NetworkDegrees:   cfg.Search.Network,
+		Industries:       cfg.Search.Industries,
+		Schools:          cfg.Search.Schools,
+		ProfileLanguages: cfg.Search.ProfileLanguages,
+		PageLimit:        cfg.Search.PageLimit,
+	}
+	var saved *storage.SavedSearch
+	var err error
+	if savedSearch != "" {
+		saved, err = store.GetSavedSearch(savedSearch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load saved search: %w", err)
+		}
+		if saved == nil {
+			return nil, fmt.Errorf("no saved search named %q", savedSearch)
+		}
+		searchCriteria, err = search.ParseSearchURL(saved.SearchURL)
 		if err != nil {
-			log.Printf("Failed to update status for %s: %v", profileURLs[0], err)
+			return nil, fmt.Errorf("saved search %q is invalid: %w", saved.Name, err)
+		}
+		searchCriteria.PageLimit = cfg.Search.PageLimit
+		log.Printf("Using saved search %q", saved.Name)
+
+		if rerun && !saved.LastRunAt.IsZero() {
+			seen, err := store.GetSavedSearchProfileURLs(saved.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load profiles from earlier runs: %w", err)
+			}
+			searcher.MarkVisited(seen)
+			searchCriteria.StopAtKnownPage = true
+			log.Printf("Re-running %q: skipping %d profiles seen since %s", saved.Name, len(seen), saved.LastRunAt.Format(time.RFC1123))
 		}
-		simulatedAcceptedConnections = append(simulatedAcceptedConnections, profileURLs[0])
 	}
 
+	log.Printf("Starting user search with criteria: %+v", searchCriteria)
+	results, err := searcher.SearchUsers(searchCriteria)
+	if err != nil {
+		return nil, err
+	}
 
-	log.Println("Sending follow-up messages to simulated accepted connections...")
-	for _, profileURL := range simulatedAcceptedConnections {
-		template := "Hello {{Name}}, thanks for connecting! I'm {{MyName}}, a {{MyTitle}}. I was particularly interested in your work on {{Interest}}. Let's chat more about it sometime."
-		variables := map[string]string{
-			"Name":     "Connection Name", // This would be dynamically extracted
-			"MyName":   "Your Name",
-			"MyTitle":  "Your Job Title",
-			"Interest": "Go-based automation tools",
+	if saved != nil && recordRun {
+		foundURLs := make([]string, 0, len(results))
+		for _, result := range results {
+			foundURLs = append(foundURLs, result.ProfileURL)
+		}
+		if err := store.RecordSavedSearchRun(saved.ID, foundURLs, time.Now()); err != nil {
+			log.Printf("Warning: failed to record run of saved search %q: %v", saved.Name, err)
 		}
+		if rerun && !saved.LastRunAt.IsZero() {
+			log.Printf("%d new profiles since the previous run of %q at %s", len(results), saved.Name, saved.LastRunAt.Format(time.RFC1123))
+		}
+	}
 
-		if err := messenger.SendFollowUpMessage(profileURL, template, variables); err != nil {
-			log.Printf("Failed to send follow-up message to %s: %v", profileURL, err)
+	log.Printf("Found %d unique profiles:", len(results))
+	for _, result := range results {
+		log.Printf("[page %d #%d] %s - %s (%s, degree %d, %d mutual, action %q)", result.Page, result.Position, result.Name, result.Headline, result.Location, result.ConnectionDegree, result.MutualConnections, result.PrimaryAction)
+	}
+	return results, nil
+}
+
+// queueSearchResults queues the profiles found by a search for the campaign.
+// Profiles the campaign already has are not queued again; everything else is
+// left to the exclusion rules, so dropped profiles are recorded.
+func queueSearchResults(store *storage.Storage, campaign string, results []search.SearchResult) {
+	queued := 0
+	for _, found := range searchProfiles(campaign, results, time.Now()) {
+		added, err := store.QueueCampaignProfile(&found)
+		if err != nil {
+			log.Printf("Failed to queue %s: %v", found.ProfileURL, err)
+			continue
+		}
+		if added {
+			queued++
 		}
-		stealth.RandomDelay(10*time.Second, 30*time.Second) // Human-like delay between messages
 	}
+	log.Printf("Queued %d new profiles for campaign %q", queued, campaign)
+}
 
-	log.Println("Automation task completed.")
+// searchProfiles turns search results into campaign profiles added at now.
+func searchProfiles(campaign string, results []search.SearchResult, now time.Time) []storage.CampaignProfile {
+	profiles := make([]storage.CampaignProfile, 0, len(results))
+	for _, result := range results {
+		profiles = append(profiles, storage.CampaignProfile{
+			Campaign:          campaign,
+			ProfileURL:        result.ProfileURL,
+			Name:              result.Name,
+			Headline:          result.Headline,
+			Location:          result.Location,
+			ConnectionDegree:  result.ConnectionDegree,
+			MutualConnections: result.MutualConnections,
+			Source:            "search",
+			AddedAt:           now,
+		})
+	}
+	return profiles
 }
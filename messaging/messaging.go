@@ -1,33 +1,51 @@
 package messaging
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
-	"linkedin-automation/stealth" // Import stealth for human-like interactions
-	"linkedin-automation/storage" // Import storage for persistence
+	"linkedin-automation/artifacts" // Import artifacts to capture failures
+	"linkedin-automation/budget"    // Import budget to cap profile visits per run
+	"linkedin-automation/config"    // Import config for the live limits
+	"linkedin-automation/retry"     // Import retry to retry transient failures
+	"linkedin-automation/stealth"   // Import stealth for human-like interactions
+	"linkedin-automation/storage"   // Import storage for persistence
+	"linkedin-automation/templates" // Import templates to fill in variables
+	"linkedin-automation/textlimit" // Import textlimit to enforce the message length
 )
 
+// ErrDailyLimit is returned when limits.daily_messages messages were already
+// sent today.
+var ErrDailyLimit = errors.New("daily message limit reached")
+
 // Messenger handles sending follow-up messages on LinkedIn.
 type Messenger struct {
 	Browser *rod.Browser
 	Page    *rod.Page
-	Storage *storage.Storage // Reference to storage for persistence
+	Storage *storage.Storage    // Reference to storage for persistence
+	Budget  *budget.Budget      // Caps profile visits per run; nil is unlimited
+	Limits  config.LimitsSource // Read on every message so config reloads apply immediately
+	// Artifacts captures evidence of failed messages; nil disables it.
+	Artifacts *artifacts.Recorder
+	// Retry retries messages failing with transient browser errors; the zero value tries once.
+	Retry retry.Policy
 }
 
 // NewMessenger creates a new Messenger instance.
-func NewMessenger(browser *rod.Browser, store *storage.Storage) *Messenger {
+func NewMessenger(browser *rod.Browser, store *storage.Storage, limits config.LimitsSource) *Messenger {
 	return &Messenger{
 		Browser: browser,
 		Storage: store,
+		Limits:  limits,
 	}
 }
 
 // SendFollowUpMessage sends a personalized message to a connection.
 // For simplicity, we assume we have the profile URL of an accepted connection.
+// Transient browser failures are retried according to Retry.
 func (m *Messenger) SendFollowUpMessage(profileURL, template string, variables map[string]string) error {
 	if m.Browser == nil {
 		return fmt.Errorf("browser not launched")
@@ -43,11 +61,74 @@ func (m *Messenger) SendFollowUpMessage(profileURL, template string, variables m
 		return nil // Or return a specific error
 	}
 
-	// Substitute variables into the template
-	message := applyTemplate(template, variables)
+	// Check daily limit
+	messagesToday, err := m.Storage.GetCountOfMessagesToday()
+	if err != nil {
+		return fmt.Errorf("failed to get count of messages sent today: %w", err)
+	}
+	if dailyLimit := m.Limits.Limits().DailyMessages; messagesToday >= dailyLimit {
+		return fmt.Errorf("%w (%d). Sent %d today.", ErrDailyLimit, dailyLimit, messagesToday)
+	}
 
+	// Render and check the length before visiting the profile
+	message, err := RenderMessage(template, variables, m.Limits.Limits())
+	if err != nil {
+		return fmt.Errorf("message for %s: %w", profileURL, err)
+	}
+
+	_, err = m.Retry.Do("Follow-up message to "+profileURL, func(attempt int) error {
+		return m.sendFollowUpMessage(profileURL, template, message, attempt)
+	})
+	return err
+}
+
+// RenderMessage substitutes variables into a follow-up template and enforces
+// the message length the way the site counts it.
+func RenderMessage(template string, variables map[string]string, limits config.LimitsConfig) (string, error) {
+	message := templates.Render(template, variables)
+	limited, err := textlimit.Enforce(message, limits.MessageMaxLength, textlimit.Overflow(limits.MessageOverflow))
+	if err != nil {
+		return "", err
+	}
+	if limited != message {
+		log.Printf("Follow-up message truncated from %d to %d characters", textlimit.Length(message), textlimit.Length(limited))
+	}
+	return limited, nil
+}
+
+// sendFollowUpMessage makes one attempt (starting at 1) at visiting a
+// connection's profile and sending the rendered message.
+func (m *Messenger) sendFollowUpMessage(profileURL, template, message string, attempt int) (err error) {
 	// Navigate to the connection's profile page
-	m.Page = m.Browser.MustPage(profileURL).MustWaitLoad()
+	if err := m.Budget.SpendProfileVisit(); err != nil {
+		return err
+	}
+	m.Page = nil
+	var page *rod.Page // The tab without the attempt's deadline, still usable for evidence after a timeout
+	defer func() {
+		// Rod's Must helpers panic; turn that into an error so it can be retried
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = panicErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+		// Keep evidence of whatever went wrong on the profile page
+		if err != nil && page != nil {
+			m.Artifacts.Capture(page, storage.ActionMessage, profileURL, attempt, err)
+		}
+		// Close the tab, so retries and later actions do not pile up open tabs
+		if page != nil {
+			page.Close()
+		}
+	}()
+	page = m.Browser.MustPage("")
+	// Bound the attempt, so a profile that never finishes loading times out and is retried
+	var cancel func()
+	m.Page, cancel = m.Retry.WithTimeout(page)
+	defer cancel()
+	m.Page.MustNavigate(profileURL).MustWaitLoad()
 	if err := stealth.ApplyPageStealth(m.Page); err != nil {
 		log.Printf("Warning: Failed to apply stealth to message page: %v", err)
 	}
@@ -95,6 +176,7 @@ func (m *Messenger) SendFollowUpMessage(profileURL, template string, variables m
 		Message:      message,
 		SentAt:       time.Now(),
 		TemplateUsed: template, // Or a template ID
+		Attempts:     attempt,
 	}
 	if err := m.Storage.SaveMessageRecord(msgRecord); err != nil {
 		return fmt.Errorf("failed to save message record to database: %w", err)
@@ -104,15 +186,6 @@ func (m *Messenger) SendFollowUpMessage(profileURL, template string, variables m
 	return nil
 }
 
-// applyTemplate substitutes variables in a message template.
-func applyTemplate(template string, variables map[string]string) string {
-	result := template
-	for key, value := range variables {
-		result = strings.ReplaceAll(result, fmt.Sprintf("{{%s}}", key), value)
-	}
-	return result
-}
-
 // DetectNewConnections uses storage to find profiles with accepted requests that haven't received a message.
 func (m *Messenger) DetectNewConnections() ([]string, error) {
 	log.Println("Attempting to detect new connections from storage for messaging...")
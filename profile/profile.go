@@ -0,0 +1,202 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"linkedin-automation/budget"  // Import budget to cap profile visits per run
+	"linkedin-automation/stealth" // Import stealth for human-like interactions
+	"linkedin-automation/storage" // Import storage for persistence
+)
+
+// Scraper reads personalization details from member profile pages.
+type Scraper struct {
+	Browser *rod.Browser
+	Page    *rod.Page
+	Storage *storage.Storage // Scraped details are saved here
+	Budget  *budget.Budget   // Caps profile visits per run; nil is unlimited
+}
+
+// NewScraper creates a new Scraper instance.
+func NewScraper(browser *rod.Browser, store *storage.Storage) *Scraper {
+	return &Scraper{
+		Browser: browser,
+		Storage: store,
+	}
+}
+
+// Scrape visits a profile, extracts its details and stores them.
+func (s *Scraper) Scrape(profileURL string) (*storage.ProfileDetails, error) {
+	if s.Browser == nil {
+		return nil, fmt.Errorf("browser not launched")
+	}
+
+	if err := s.Budget.SpendProfileVisit(); err != nil {
+		return nil, err
+	}
+	page, err := s.Browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile page: %w", err)
+	}
+	defer page.Close()
+	s.Page = page
+	if err := page.Navigate(profileURL); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", profileURL, err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", profileURL, err)
+	}
+	if err := stealth.ApplyPageStealth(s.Page); err != nil {
+		log.Printf("Warning: Failed to apply stealth to profile page: %v", err)
+	}
+	stealth.RandomDelay(2*time.Second, 5*time.Second) // Simulate reading profile
+
+	details, err := Extract(s.Page, profileURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Storage.SaveProfileDetails(details); err != nil {
+		return nil, err
+	}
+	log.Printf("Scraped profile %s: %s %s, %s at %s", profileURL, details.FirstName, details.LastName, details.Position, details.Company)
+	return details, nil
+}
+
+// rawDetails is what extractDetailsJS returns.
+type rawDetails struct {
+	Name           string `json:"name"`
+	Headline       string `json:"headline"`
+	Location       string `json:"location"`
+	CurrentCompany string `json:"currentCompany"` // aria-label of the top card company button
+	Position       string `json:"position"`       // First entry of the experience section
+	Company        string `json:"company"`        // e.g. "Acme · Full-time"
+	About          string `json:"about"`
+}
+
+// extractDetailsJS reads the top card, the first experience entry and the
+// About section of a profile page. Parsing happens in Go.
+const extractDetailsJS = `() => {
+	const text = (root, sel) => {
+		const el = root && root.querySelector(sel);
+		return el ? el.innerText.trim() : '';
+	};
+	const section = id => {
+		const anchor = document.getElementById(id);
+		return anchor ? anchor.closest('section') : null;
+	};
+	const companyButton = document.querySelector('button[aria-label^="Current company"]');
+	const experience = section('experience');
+	const firstJob = experience ? experience.querySelector('li') : null;
+	return JSON.stringify({
+		name: text(document, 'h1'),
+		headline: text(document, '.text-body-medium.break-words'),
+		location: text(document, '.text-body-small.inline.t-black--light.break-words'),
+		currentCompany: companyButton ? companyButton.getAttribute('aria-label') : '',
+		position: text(firstJob, '.t-bold span[aria-hidden="true"]'),
+		company: text(firstJob, '.t-14.t-normal span[aria-hidden="true"]'),
+		about: text(section('about'), '.inline-show-more-text span[aria-hidden="true"]') || text(section('about'), '.display-flex span[aria-hidden="true"]'),
+	});
+}`
+
+// Extract reads the details of the profile currently loaded in page, so
+// flows that already visit a profile can record it without another visit.
+func Extract(page *rod.Page, profileURL string) (*storage.ProfileDetails, error) {
+	obj, err := page.Eval(extractDetailsJS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract profile details: %w", err)
+	}
+	var raw rawDetails
+	if err := json.Unmarshal([]byte(obj.Value.Str()), &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode profile details: %w", err)
+	}
+	if strings.TrimSpace(raw.Name) == "" {
+		return nil, fmt.Errorf("no profile name found on %s", profileURL)
+	}
+	details := buildDetails(raw)
+	details.ProfileURL = profileURL
+	details.ScrapedAt = time.Now()
+	return details, nil
+}
+
+// buildDetails turns the raw page text into ProfileDetails.
+func buildDetails(raw rawDetails) *storage.ProfileDetails {
+	firstName, lastName := splitName(raw.Name)
+	details := &storage.ProfileDetails{
+		FirstName: firstName,
+		LastName:  lastName,
+		Headline:  strings.TrimSpace(raw.Headline),
+		Position:  strings.TrimSpace(raw.Position),
+		Location:  strings.TrimSpace(raw.Location),
+		About:     strings.TrimSpace(raw.About),
+	}
+
+	// "Current company: Acme. Click to skip to experience card"
+	if label := strings.TrimSpace(strings.TrimPrefix(raw.CurrentCompany, "Current company:")); label != raw.CurrentCompany {
+		details.Company = strings.TrimSpace(strings.SplitN(label, ". Click", 2)[0])
+	}
+	// "Acme · Full-time"
+	if details.Company == "" {
+		details.Company = strings.TrimSpace(strings.SplitN(raw.Company, "·", 2)[0])
+	}
+	// Fall back to a "Position at Company" headline.
+	if details.Position == "" || details.Company == "" {
+		if i := strings.LastIndex(details.Headline, " at "); i > 0 {
+			if details.Position == "" {
+				details.Position = strings.TrimSpace(details.Headline[:i])
+			}
+			if details.Company == "" {
+				details.Company = strings.TrimSpace(details.Headline[i+len(" at "):])
+			}
+		}
+	}
+	return details
+}
+
+// credentialsPattern matches trailing credentials and pronouns such as
+// ", PhD", " (She/Her)" or " - MBA".
+var credentialsPattern = regexp.MustCompile(`\s*(,|\(| - | – ).*$`)
+
+// splitName splits a display name into first and last name, ignoring
+// credentials. Everything after the first word is the last name.
+func splitName(name string) (string, string) {
+	name = credentialsPattern.ReplaceAllString(strings.TrimSpace(name), "")
+	fields := strings.Fields(name)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return fields[0], ""
+	default:
+		return fields[0], strings.Join(fields[1:], " ")
+	}
+}
+
+// Variables returns the template variables for a profile: Name (the full
+// name), FirstName, LastName, Headline, Position, Company, Location and About.
+// Empty details are left out so they do not overwrite other sources.
+func Variables(details *storage.ProfileDetails) map[string]string {
+	variables := make(map[string]string)
+	if details == nil {
+		return variables
+	}
+	set := func(name, value string) {
+		if value != "" {
+			variables[name] = value
+		}
+	}
+	set("Name", strings.TrimSpace(details.FirstName+" "+details.LastName))
+	set("FirstName", details.FirstName)
+	set("LastName", details.LastName)
+	set("Headline", details.Headline)
+	set("Position", details.Position)
+	set("Company", details.Company)
+	set("Location", details.Location)
+	set("About", details.About)
+	return variables
+}
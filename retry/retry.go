@@ -0,0 +1,119 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+)
+
+// Policy retries actions that fail with transient browser errors, waiting
+// exponentially longer between attempts. The zero Policy tries once.
+type Policy struct {
+	Attempts     int           // Total attempts, including the first; below 1 means 1
+	InitialDelay time.Duration // Delay before the first retry, doubled for each further retry
+	MaxDelay     time.Duration // Cap on the delay between attempts; 0 means no cap
+	// AttemptTimeout bounds the page operations of one attempt, see WithTimeout; 0 means no deadline.
+	AttemptTimeout time.Duration
+}
+
+// WithTimeout returns a copy of page whose operations fail with
+// context.DeadlineExceeded once the attempt has taken AttemptTimeout, so a
+// page that never finishes loading is retried instead of hanging the run.
+// Call cancel when the attempt ends. Without AttemptTimeout, page is returned
+// as is.
+func (p Policy) WithTimeout(page *rod.Page) (timed *rod.Page, cancel func()) {
+	if p.AttemptTimeout <= 0 {
+		return page, func() {}
+	}
+	timed = page.Timeout(p.AttemptTimeout)
+	return timed, func() { timed.CancelTimeout() }
+}
+
+// Do calls fn until it succeeds, fails with a permanent error or runs out of
+// attempts, and returns the number of attempts made with the last error.
+// fn is passed the 1-based attempt number. Panics of rod's Must* helpers are
+// recovered and treated like returned errors.
+func (p Policy) Do(name string, fn func(attempt int) error) (int, error) {
+	attempts := p.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		var err error
+		if panicErr := rod.Try(func() { err = fn(attempt) }); panicErr != nil {
+			// Drop the stack trace rod adds, keeping the error that was raised.
+			var tryErr *rod.TryError
+			if errors.As(panicErr, &tryErr) {
+				err = tryErr.Unwrap()
+			} else {
+				err = panicErr
+			}
+		}
+		if err == nil || !IsTransient(err) || attempt >= attempts {
+			return attempt, err
+		}
+		delay := p.delay(attempt)
+		log.Printf("%s failed with a transient error (attempt %d of %d), retrying in %s: %v", name, attempt, attempts, delay.Round(time.Millisecond), err)
+		time.Sleep(delay)
+	}
+}
+
+// delay returns how long to wait after the given failed attempt: the
+// initial delay doubled for each earlier retry, capped at MaxDelay, with
+// jitter so retries do not fall into a detectable rhythm.
+func (p Policy) delay(attempt int) time.Duration {
+	delay := p.InitialDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Wait somewhere between half and all of the delay.
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// IsTransient reports whether err is worth retrying: a timeout, an aborted
+// navigation, or an element that went stale or was covered while the page
+// was still changing. Everything else, such as a reached limit or a profile
+// that cannot be connected with, is permanent.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var navigationErr *rod.NavigationError
+	var objectErr *rod.ObjectNotFoundError
+	var elementErr *rod.ElementNotFoundError
+	var interactableErr *rod.NotInteractableError
+	var coveredErr *rod.CoveredError
+	var shapeErr *rod.InvisibleShapeError
+	if errors.As(err, &navigationErr) || errors.As(err, &objectErr) || errors.As(err, &elementErr) ||
+		errors.As(err, &interactableErr) || errors.As(err, &coveredErr) || errors.As(err, &shapeErr) {
+		return true
+	}
+
+	// Stale elements and execution contexts, left behind by a re-render or a
+	// navigation in the middle of an action.
+	// Compared by code and message, since the Data of live errors varies.
+	var cdpErr *cdp.Error
+	if errors.As(err, &cdpErr) {
+		for _, stale := range []*cdp.Error{cdp.ErrCtxNotFound, cdp.ErrCtxDestroyed, cdp.ErrObjNotFound, cdp.ErrNodeNotFoundAtPos, cdp.ErrNotAttachedToActivePage} {
+			if cdpErr.Code == stale.Code && cdpErr.Message == stale.Message {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package schedule
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"linkedin-automation/config"   // Import config for the schedule settings
+	"linkedin-automation/holidays" // Import holidays for the days off
+)
+
+// horizon bounds the search for the next working window, so a schedule
+// without any working day does not loop forever.
+const horizon = 400 * 24 * time.Hour
+
+// Scheduler decides when actions may run: inside the working windows of the
+// day of the week, in the account's timezone, except on holidays or while
+// paused.
+type Scheduler struct {
+	Paused        bool
+	Location      *time.Location
+	Days          [7][]config.Window // Indexed by time.Weekday; empty for a day off
+	Holidays      *holidays.Calendar
+	SpreadActions bool // Pace actions evenly over the rest of the working window
+}
+
+// New creates a Scheduler from the schedule config, loading the holiday
+// calendar if one is configured.
+func New(cfg config.ScheduleConfig) (*Scheduler, error) {
+	loc, err := cfg.Location()
+	if err != nil {
+		return nil, err
+	}
+	s := &Scheduler{Paused: cfg.Paused, Location: loc, SpreadActions: cfg.Spread}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if s.Days[day], err = cfg.Windows(day); err != nil {
+			return nil, fmt.Errorf("invalid working hours for %s: %w", day, err)
+		}
+	}
+	if cfg.HolidaysFile != "" {
+		if s.Holidays, err = holidays.Load(cfg.HolidaysFile, loc); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// windows returns the working windows of the day t falls on, and the
+// holiday, if any, that cancels them.
+func (s *Scheduler) windows(t time.Time) ([]config.Window, *holidays.Holiday) {
+	if holiday := s.Holidays.On(t); holiday != nil {
+		return nil, holiday
+	}
+	return s.Days[t.Weekday()], nil
+}
+
+// at returns the time minute minutes after the midnight starting day.
+func at(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minute, 0, 0, day.Location())
+}
+
+// current returns the working window containing t as times, if any.
+func (s *Scheduler) current(t time.Time) (start, end time.Time, ok bool) {
+	t = t.In(s.Location)
+	windows, _ := s.windows(t)
+	for _, window := range windows {
+		start, end = at(t, window.Start), at(t, window.End)
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// Active reports whether actions may run at t.
+func (s *Scheduler) Active(t time.Time) bool {
+	_, _, ok := s.current(t)
+	return !s.Paused && ok
+}
+
+// Next returns the first time at or after t when actions may run. It
+// returns false while paused or if no working window comes up within a year.
+func (s *Scheduler) Next(t time.Time) (time.Time, bool) {
+	if s.Paused {
+		return time.Time{}, false
+	}
+	t = t.In(s.Location)
+	for day := at(t, 0); day.Sub(t) < horizon; day = at(day.AddDate(0, 0, 1), 0) {
+		windows, _ := s.windows(day)
+		for _, window := range windows {
+			start, end := at(day, window.Start), at(day, window.End)
+			if end.After(t) {
+				if start.Before(t) {
+					return t, true
+				}
+				return start, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Reason explains why actions may not run at t, for logging.
+func (s *Scheduler) Reason(t time.Time) string {
+	if s.Paused {
+		return "outreach is paused"
+	}
+	if _, holiday := s.windows(t.In(s.Location)); holiday != nil {
+		return fmt.Sprintf("today is a holiday (%s)", holiday.Name)
+	}
+	return "outside working hours"
+}
+
+// Spread returns the gap to leave after an action at t so that remaining
+// further actions are spread evenly over the rest of the working window.
+// It returns 0 if spreading is off or nothing remains.
+func (s *Scheduler) Spread(t time.Time, remaining int) time.Duration {
+	if !s.SpreadActions || remaining <= 0 {
+		return 0
+	}
+	_, end, ok := s.current(t)
+	if !ok {
+		return 0
+	}
+	// Leave room after the last action too, so it does not land at the very end.
+	return end.Sub(t) / time.Duration(remaining+1)
+}
+
+// Source keeps a Scheduler in line with the live config, rebuilding it when
+// the schedule settings or the holidays file change.
+type Source struct {
+	live *config.Live
+
+	mu        sync.Mutex
+	cfg       config.ScheduleConfig
+	modTime   time.Time
+	scheduler *Scheduler
+}
+
+// NewSource creates a Source reading the schedule from live.
+func NewSource(live *config.Live) *Source {
+	return &Source{live: live}
+}
+
+// Current returns the Scheduler for the current config. If a changed holidays
+// file cannot be read, the previous Scheduler is kept.
+func (src *Source) Current() *Scheduler {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	cfg := src.live.Current().Schedule
+	var modTime time.Time
+	if cfg.HolidaysFile != "" {
+		if info, err := os.Stat(cfg.HolidaysFile); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+	if src.scheduler != nil && reflect.DeepEqual(cfg, src.cfg) && modTime.Equal(src.modTime) {
+		return src.scheduler
+	}
+
+	wanted := cfg
+	scheduler, err := New(cfg)
+	if err != nil {
+		if src.scheduler == nil {
+			// Nothing to fall back on; the config was validated, so only the
+			// holidays file can be at fault. Work without it.
+			log.Printf("Warning: %v; ignoring holidays", err)
+			cfg.HolidaysFile = ""
+			if scheduler, err = New(cfg); err != nil {
+				log.Printf("Warning: %v", err)
+				scheduler = &Scheduler{Paused: true, Location: time.Local}
+			}
+		} else {
+			log.Printf("Warning: keeping the previous schedule: %v", err)
+			scheduler = src.scheduler
+		}
+	} else if src.scheduler != nil && scheduler.Holidays != nil {
+		log.Printf("Schedule reloaded with %d holidays", len(scheduler.Holidays.Holidays))
+	}
+	src.cfg, src.modTime, src.scheduler = wanted, modTime, scheduler
+	return scheduler
+}
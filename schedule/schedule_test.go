@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"linkedin-automation/config"
+	"linkedin-automation/holidays"
+)
+
+// testScheduler works 09:00-12:00 and 13:00-17:00 on weekdays, in Berlin,
+// with Christmas and a yearly New Year off.
+func testScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available:", err)
+	}
+	s := &Scheduler{Location: berlin, Holidays: &holidays.Calendar{Holidays: []holidays.Holiday{
+		{Name: "Christmas", Start: time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)},
+		{Name: "New Year", Start: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Yearly: true},
+	}}}
+	for day := time.Monday; day <= time.Friday; day++ {
+		s.Days[day] = []config.Window{{Start: 9 * 60, End: 12 * 60}, {Start: 13 * 60, End: 17 * 60}}
+	}
+	return s
+}
+
+func TestNext(t *testing.T) {
+	s := testScheduler(t)
+	berlin := s.Location
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{"inside a window", time.Date(2026, 10, 19, 10, 30, 0, 0, berlin), time.Date(2026, 10, 19, 10, 30, 0, 0, berlin)},
+		{"start of a window", time.Date(2026, 10, 19, 9, 0, 0, 0, berlin), time.Date(2026, 10, 19, 9, 0, 0, 0, berlin)},
+		{"before the first window", time.Date(2026, 10, 19, 7, 0, 0, 0, berlin), time.Date(2026, 10, 19, 9, 0, 0, 0, berlin)},
+		{"lunch break", time.Date(2026, 10, 19, 12, 0, 0, 0, berlin), time.Date(2026, 10, 19, 13, 0, 0, 0, berlin)},
+		{"after the last window", time.Date(2026, 10, 19, 17, 0, 0, 0, berlin), time.Date(2026, 10, 20, 9, 0, 0, 0, berlin)},
+		{"Friday evening to Monday", time.Date(2026, 10, 23, 18, 0, 0, 0, berlin), time.Date(2026, 10, 26, 9, 0, 0, 0, berlin)},
+		{"other timezone", time.Date(2026, 10, 19, 5, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 0, 0, 0, berlin)},
+		{"over Christmas and the weekend", time.Date(2026, 12, 23, 17, 30, 0, 0, berlin), time.Date(2026, 12, 28, 9, 0, 0, 0, berlin)},
+		{"yearly holiday spanning New Year", time.Date(2026, 12, 30, 17, 30, 0, 0, berlin), time.Date(2027, 1, 4, 9, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		got, ok := s.Next(tt.at)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%s) = %s, %v, want %s", tt.name, tt.at, got, ok, tt.want)
+		}
+	}
+
+	s.Paused = true
+	if _, ok := s.Next(time.Date(2026, 10, 19, 10, 0, 0, 0, berlin)); ok {
+		t.Error("paused: want no next window")
+	}
+	if _, ok := (&Scheduler{Location: berlin}).Next(time.Now()); ok {
+		t.Error("no working days: want no next window")
+	}
+}
+
+func TestActive(t *testing.T) {
+	s := testScheduler(t)
+	berlin := s.Location
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 19, 9, 0, 0, 0, berlin), true},
+		{time.Date(2026, 10, 19, 12, 0, 0, 0, berlin), false}, // Windows end exclusive
+		{time.Date(2026, 10, 24, 10, 0, 0, 0, berlin), false}, // Saturday
+		{time.Date(2026, 12, 24, 10, 0, 0, 0, berlin), false}, // Christmas
+		{time.Date(2027, 1, 1, 10, 0, 0, 0, berlin), false},   // New Year
+	}
+	for _, tt := range tests {
+		if got := s.Active(tt.at); got != tt.want {
+			t.Errorf("Active(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestSpread(t *testing.T) {
+	s := testScheduler(t)
+	berlin := s.Location
+	s.SpreadActions = true
+	tests := []struct {
+		name      string
+		at        time.Time
+		remaining int
+		want      time.Duration
+	}{
+		{"rest of the morning", time.Date(2026, 10, 19, 10, 0, 0, 0, berlin), 3, 30 * time.Minute},
+		{"one left", time.Date(2026, 10, 19, 13, 0, 0, 0, berlin), 1, 2 * time.Hour},
+		{"nothing left", time.Date(2026, 10, 19, 10, 0, 0, 0, berlin), 0, 0},
+		{"outside the windows", time.Date(2026, 10, 19, 12, 30, 0, 0, berlin), 3, 0},
+		{"holiday", time.Date(2026, 12, 24, 10, 0, 0, 0, berlin), 3, 0},
+	}
+	for _, tt := range tests {
+		if got := s.Spread(tt.at, tt.remaining); got != tt.want {
+			t.Errorf("%s: Spread = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	s.SpreadActions = false
+	if got := s.Spread(time.Date(2026, 10, 19, 10, 0, 0, 0, berlin), 3); got != 0 {
+		t.Errorf("spreading off: Spread = %s, want 0", got)
+	}
+}
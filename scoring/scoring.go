@@ -0,0 +1,145 @@
+package scoring
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"linkedin-automation/config"
+	"linkedin-automation/storage"
+)
+
+// Scorer rates profiles with the weighted rules of a campaign.
+type Scorer struct {
+	Rules config.ScoringConfig
+
+	titlePatterns     map[string]*regexp.Regexp
+	seniorityPatterns map[string]*regexp.Regexp
+}
+
+// NewScorer creates a Scorer for the given rules.
+func NewScorer(rules config.ScoringConfig) *Scorer {
+	return &Scorer{
+		Rules:             rules,
+		titlePatterns:     wordPatterns(rules.TitleKeywords),
+		seniorityPatterns: wordPatterns(rules.Seniority),
+	}
+}
+
+// Score rates profile and returns the score with a short explanation of each
+// rule that contributed to it, e.g. "title:golang +10".
+func (s *Scorer) Score(profile storage.CampaignProfile) (float64, []string) {
+	var score float64
+	var reasons []string
+	add := func(rule, value string, weight float64) {
+		score += weight
+		reasons = append(reasons, fmt.Sprintf("%s:%s %+g", rule, value, weight))
+	}
+
+	for _, keyword := range sortedKeys(s.titlePatterns) {
+		if s.titlePatterns[keyword].MatchString(profile.Headline) {
+			add("title", keyword, s.Rules.TitleKeywords[keyword])
+		}
+	}
+
+	// Only the most senior level counts, so "Senior Staff Engineer" is not
+	// rewarded twice.
+	if level, ok := best(s.Rules.Seniority, func(level string) bool {
+		return s.seniorityPatterns[level] != nil && s.seniorityPatterns[level].MatchString(profile.Headline)
+	}); ok {
+		add("seniority", level, s.Rules.Seniority[level])
+	}
+
+	if location, ok := best(s.Rules.Locations, func(location string) bool {
+		return containsFold(profile.Location, location)
+	}); ok {
+		add("location", location, s.Rules.Locations[location])
+	}
+
+	company := profile.CurrentCompany()
+	if name, ok := best(s.Rules.Companies, func(name string) bool {
+		return containsFold(company, name)
+	}); ok {
+		add("company", name, s.Rules.Companies[name])
+	}
+
+	if mutual := profile.MutualConnections; mutual > 0 && s.Rules.MutualConnectionWeight != 0 {
+		if mutual > s.Rules.MaxMutualConnections {
+			mutual = s.Rules.MaxMutualConnections
+		}
+		add("mutual_connections", fmt.Sprint(mutual), float64(mutual)*s.Rules.MutualConnectionWeight)
+	}
+
+	return score, reasons
+}
+
+// Rank scores every profile, stores the scores in the campaign's queue and
+// returns the profiles ordered from highest to lowest score. Profiles with
+// equal scores keep their order.
+func (s *Scorer) Rank(store *storage.Storage, campaign string, profiles []storage.CampaignProfile) ([]storage.CampaignProfile, error) {
+	ranked := make([]storage.CampaignProfile, len(profiles))
+	copy(ranked, profiles)
+	for i := range ranked {
+		score, reasons := s.Score(ranked[i])
+		ranked[i].Score = score
+		if err := store.UpdateCampaignProfileScore(campaign, ranked[i].ProfileURL, score); err != nil {
+			return nil, err
+		}
+		if len(reasons) > 0 {
+			log.Printf("Scored %s (%s): %g [%s]", ranked[i].ProfileURL, ranked[i].Name, score, strings.Join(reasons, ", "))
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked, nil
+}
+
+// best returns the matching key with the highest weight.
+func best(weights map[string]float64, matches func(string) bool) (string, bool) {
+	var bestKey string
+	found := false
+	for _, key := range sortedKeys(weights) {
+		if !matches(key) {
+			continue
+		}
+		if !found || weights[key] > weights[bestKey] {
+			bestKey = key
+			found = true
+		}
+	}
+	return bestKey, found
+}
+
+// wordPatterns compiles a case-insensitive whole-word pattern for each key,
+// so "lead" does not match "leader" and "vp" does not match "vpn".
+func wordPatterns(weights map[string]float64) map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp, len(weights))
+	for key := range weights {
+		if strings.TrimSpace(key) == "" {
+			continue
+		}
+		patterns[key] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(strings.TrimSpace(key)) + `\b`)
+	}
+	return patterns
+}
+
+// sortedKeys returns the keys of a map in sorted order, so scores and their
+// explanations do not depend on map iteration order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsFold reports whether substr is within s, ignoring case and
+// surrounding whitespace. An empty substr never matches.
+func containsFold(s, substr string) bool {
+	substr = strings.ToLower(strings.TrimSpace(substr))
+	return substr != "" && strings.Contains(strings.ToLower(s), substr)
+}
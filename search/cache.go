@@ -0,0 +1,80 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"linkedin-automation/budget"
+	"linkedin-automation/storage"
+)
+
+// cachingPager serves result pages from the search cache in storage while
+// they are younger than ttl, and stores every page fetched from next. Cache
+// entries are keyed by the canonical search URL, which BuildSearchURL makes
+// identical for identical criteria.
+type cachingPager struct {
+	next     resultPager
+	store    *storage.Storage
+	queryKey string
+	ttl      time.Duration
+	budget   *budget.Budget // Cache hits are reported to it
+	refresh  bool           // Skip cache reads, but still store fetched pages
+}
+
+// LoadPage returns the cached page n if it is fresh, and otherwise loads and caches it.
+func (p *cachingPager) LoadPage(n int) ([]SearchResult, bool, error) {
+	if !p.refresh {
+		cached, err := p.store.GetCachedSearchPage(p.queryKey, n, time.Now().UTC().Add(-p.ttl))
+		if err != nil {
+			log.Printf("Warning: search cache unavailable: %v", err)
+		} else if cached != nil {
+			var results []SearchResult
+			if err := json.Unmarshal([]byte(cached.Results), &results); err != nil {
+				log.Printf("Warning: ignoring unreadable cached page %d: %v", n, err)
+			} else {
+				log.Printf("Using cached results for page %d (fetched %s).", n, cached.FetchedAt.Format(time.RFC1123))
+				p.budget.RecordCachedPage()
+				return results, cached.HasNext, nil
+			}
+		}
+	}
+
+	results, hasNext, err := p.next.LoadPage(n)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(results) == 0 {
+		// Could be a page that failed to render; do not keep it around.
+		return results, hasNext, nil
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode results for the cache: %w", err)
+	}
+	if err := p.store.SaveCachedSearchPage(&storage.CachedSearchPage{
+		QueryKey:  p.queryKey,
+		Page:      n,
+		Results:   string(data),
+		HasNext:   hasNext,
+		FetchedAt: time.Now().UTC(), // UTC, so timestamps compare correctly as text
+	}); err != nil {
+		log.Printf("Warning: failed to cache results page %d: %v", n, err)
+	}
+	return results, hasNext, nil
+}
+
+// budgetPager charges every page it loads from next against the run budget.
+type budgetPager struct {
+	next   resultPager
+	budget *budget.Budget
+}
+
+// LoadPage spends one result page of the budget, then loads page n.
+func (p *budgetPager) LoadPage(n int) ([]SearchResult, bool, error) {
+	if err := p.budget.SpendResultPage(); err != nil {
+		return nil, false, err
+	}
+	return p.next.LoadPage(n)
+}
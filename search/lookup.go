@@ -0,0 +1,87 @@
+package search
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/go-rod/rod/lib/proto"
+	"linkedin-automation/stealth" // Import stealth for human-like interactions
+)
+
+// typeaheadFacet says how to look a facet value up through the search
+// typeahead: the typeahead type to ask for and the URN holding the ID.
+type typeaheadFacet struct {
+	kind string
+	urn  *regexp.Regexp
+}
+
+// typeaheadFacets covers the facets whose values are names. Network degree and
+// profile language values are fixed codes and never looked up.
+var typeaheadFacets = map[Facet]typeaheadFacet{
+	FacetCurrentCompany: {"COMPANY", regexp.MustCompile(`urn:li:(?:fs_miniCompany|fsd_company|company):(\d+)`)},
+	FacetPastCompany:    {"COMPANY", regexp.MustCompile(`urn:li:(?:fs_miniCompany|fsd_company|company):(\d+)`)},
+	FacetGeo:            {"GEO", regexp.MustCompile(`urn:li:(?:fs_geo|fsd_geo|geo):(\d+)`)},
+	FacetIndustry:       {"INDUSTRY", regexp.MustCompile(`urn:li:(?:fs_industry|fsd_industry|industry):(\d+)`)},
+	FacetSchool:         {"SCHOOL", regexp.MustCompile(`urn:li:(?:fs_miniSchool|fsd_school|school):(\d+)`)},
+}
+
+// typeaheadJS asks the search typeahead for matches, the same request the
+// facet filter boxes make while typing. It runs on a linkedin.com page so the
+// session cookies apply; the CSRF token is the JSESSIONID cookie.
+const typeaheadJS = `async (kind, keywords) => {
+	const match = document.cookie.match(/JSESSIONID="?([^";]+)/);
+	const response = await fetch('/voyager/api/typeahead/hitsV2?q=type&origin=OTHER&type=' + kind +
+		'&keywords=' + encodeURIComponent(keywords), {
+		credentials: 'include',
+		headers: {'accept': 'application/json', 'csrf-token': match ? match[1] : ''},
+	});
+	if (!response.ok) {
+		throw new Error('typeahead returned HTTP ' + response.status);
+	}
+	return await response.text();
+}`
+
+// lookupFacet is the Searcher's FacetLookup. It opens a tab on the feed, asks
+// the typeahead for name and takes the ID of the first match.
+func (s *Searcher) lookupFacet(facet Facet, name string) (string, error) {
+	typeahead, ok := typeaheadFacets[facet]
+	if !ok {
+		return "", fmt.Errorf("%s values cannot be looked up", facet)
+	}
+	if s.Browser == nil {
+		return "", fmt.Errorf("browser not launched")
+	}
+	log.Printf("Looking up the %s ID of %q", facet, name)
+
+	page, err := s.Browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return "", fmt.Errorf("failed to open lookup page: %w", err)
+	}
+	defer page.Close()
+	if err := stealth.ApplyPageStealth(page); err != nil {
+		log.Printf("Warning: Failed to apply stealth to lookup page: %v", err)
+	}
+	if err := page.Navigate("https://www.linkedin.com/feed/"); err != nil {
+		return "", fmt.Errorf("failed to open the feed for the lookup: %w", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		return "", fmt.Errorf("failed to load the feed for the lookup: %w", err)
+	}
+
+	obj, err := page.Eval(typeaheadJS, typeahead.kind, name)
+	if err != nil {
+		return "", fmt.Errorf("typeahead lookup failed: %w", err)
+	}
+	return firstURNID(typeahead.urn, obj.Value.Str())
+}
+
+// firstURNID returns the ID of the first URN matching urn in a typeahead
+// response.
+func firstURNID(urn *regexp.Regexp, response string) (string, error) {
+	match := urn.FindStringSubmatch(response)
+	if match == nil {
+		return "", fmt.Errorf("no match found")
+	}
+	return match[1], nil
+}
@@ -0,0 +1,191 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-rod/rod"
+	"linkedin-automation/budget"
+	"linkedin-automation/stealth"
+	"linkedin-automation/storage"
+)
+
+// maxSearchPages is the last result page LinkedIn serves for a search
+// (1,000 results at 10 per page); later pages come back empty.
+const maxSearchPages = 100
+
+// resultPager loads one page of search results. The browser implementation
+// navigates the live site; the interface lets the pagination logic run
+// against a fake result set.
+type resultPager interface {
+	// LoadPage returns the results on the 1-based page n and whether the
+	// site offers a page after it.
+	LoadPage(n int) (results []SearchResult, hasNext bool, err error)
+}
+
+// collectPages walks result pages from the first until the last page, an
+// empty page, the page limit (capped at maxSearchPages) or, with
+// StopAtKnownPage, a page without new profiles. Profiles already in visited
+// are skipped, and visited is updated with every profile returned.
+func collectPages(pager resultPager, criteria SearchUserCriteria, visited map[string]bool) ([]SearchResult, error) {
+	limit := criteria.PageLimit
+	if limit > maxSearchPages {
+		log.Printf("Page limit %d exceeds the %d pages LinkedIn serves; stopping at page %d.", limit, maxSearchPages, maxSearchPages)
+		limit = maxSearchPages
+	}
+
+	var results []SearchResult
+	for pageNumber := 1; pageNumber <= limit; pageNumber++ {
+		log.Printf("Scraping page %d of search results.", pageNumber)
+		pageResults, hasNext, err := pager.LoadPage(pageNumber)
+		if errors.Is(err, budget.ErrExhausted) {
+			log.Printf("Stopping search before page %d: %v", pageNumber, err)
+			break
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to load search results page %d: %w", pageNumber, err)
+		}
+		if len(pageResults) == 0 {
+			log.Printf("Page %d has no results. End of search results.", pageNumber)
+			break
+		}
+
+		newOnPage := 0
+		for _, result := range pageResults {
+			// Basic duplicate detection
+			if visited[result.ProfileURL] {
+				continue
+			}
+			visited[result.ProfileURL] = true
+			results = append(results, result)
+			newOnPage++
+			log.Printf("Found profile: %s (%s, %s)", result.ProfileURL, result.Name, result.Headline)
+		}
+
+		if criteria.StopAtKnownPage && newOnPage == 0 {
+			log.Printf("Page %d only has previously seen profiles. Stopping incremental search.", pageNumber)
+			break
+		}
+		if !hasNext {
+			log.Printf("Page %d is the last page of search results.", pageNumber)
+			break
+		}
+	}
+	return results, nil
+}
+
+// browserPager loads result pages in the browser by setting the search URL's
+// page parameter, which is more reliable than clicking the Next button. The
+// search page is only opened once a page is actually loaded.
+type browserPager struct {
+	searcher  *Searcher
+	page      *rod.Page
+	searchURL string
+}
+
+// LoadPage navigates to page n, scrolls to render every card and parses them.
+// Transient browser failures are retried according to the searcher's Retry.
+func (p *browserPager) LoadPage(n int) (results []SearchResult, hasNext bool, err error) {
+	pageURL, err := withPageNumber(p.searchURL, n)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = p.searcher.Retry.Do(fmt.Sprintf("Loading search results page %d", n), func(attempt int) error {
+		var loadErr error
+		results, hasNext, loadErr = p.loadPage(pageURL, n, attempt)
+		return loadErr
+	})
+	return results, hasNext, err
+}
+
+// loadPage makes one attempt (starting at 1) at loading the results page at
+// pageURL.
+func (p *browserPager) loadPage(pageURL string, n, attempt int) (results []SearchResult, hasNext bool, err error) {
+	if p.page == nil {
+		p.page = p.searcher.openSearchPage()
+	} else {
+		stealth.RandomDelay(1*time.Second, 3*time.Second) // Simulate human hesitation before moving on
+	}
+	defer func() {
+		// Rod's Must helpers panic; turn that into an error so it can be retried
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = panicErr
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+		// Keep evidence of a results page that could not be read
+		if err != nil {
+			p.searcher.Artifacts.Capture(p.page, storage.ActionSearch, pageURL, attempt, err)
+		}
+	}()
+	// Bound the attempt, so a results page that never settles times out and is retried
+	page, cancel := p.searcher.Retry.WithTimeout(p.page)
+	defer cancel()
+	log.Printf("Navigating to search results page: %s", pageURL)
+	if err := page.Navigate(pageURL); err != nil {
+		return nil, false, fmt.Errorf("failed to navigate to results page: %w", err)
+	}
+	if err := page.WaitStable(time.Second); err != nil {
+		return nil, false, fmt.Errorf("results page did not settle: %w", err)
+	}
+	if err := stealth.ApplyPageStealth(page); err != nil { // Re-apply after navigation
+		log.Printf("Warning: Failed to apply stealth after search navigation: %v", err)
+	}
+	stealth.RandomDelay(2*time.Second, 5*time.Second) // Simulate page load and user thinking
+
+	// Scroll to load all results on the current page
+	// LinkedIn loads results dynamically, so scrolling is often necessary.
+	lastHeight := page.MustEval("() => document.body.scrollHeight").Int()
+	for {
+		page.Mouse.Scroll(0.0, float64(int(float64(lastHeight)*0.8)), 100) // Changed to float64 for coords and int for speed
+		stealth.RandomDelay(500*time.Millisecond, 1*time.Second)
+		newHeight := page.MustEval("() => document.body.scrollHeight").Int()
+		if newHeight == lastHeight {
+			break // Scrolled to bottom
+		}
+		lastHeight = newHeight
+	}
+	stealth.RandomDelay(1*time.Second, 2*time.Second) // Simulate user reviewing results
+
+	results, err = parseResults(page, n)
+	if err != nil {
+		return nil, false, err
+	}
+	return results, hasNextPage(page), nil
+}
+
+// hasNextPage reports whether the pagination shows an enabled Next button.
+// A missing button means the results fit on a single page.
+func hasNextPage(page *rod.Page) bool {
+	buttons, err := page.Elements(`button[aria-label="Next"]`)
+	if err != nil || len(buttons) == 0 {
+		return false
+	}
+	disabled, err := buttons[0].Property("disabled")
+	if err != nil {
+		return false
+	}
+	return !disabled.Bool()
+}
+
+// withPageNumber sets the page parameter of a search URL; page 1 omits it.
+func withPageNumber(searchURL string, n int) (string, error) {
+	u, err := url.Parse(searchURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid search URL: %w", err)
+	}
+	query := u.Query()
+	if n > 1 {
+		query.Set("page", strconv.Itoa(n))
+	} else {
+		query.Del("page")
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,189 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"linkedin-automation/budget"
+)
+
+// fakePager serves a fixed result set, one slice per page, and records which
+// pages were requested. Pages past the end are empty.
+type fakePager struct {
+	pages     [][]SearchResult
+	endless   bool           // Offer a next page even after the last one
+	budget    *budget.Budget // Spent per page if set, like the browser pager
+	err       error          // Returned for page errPage
+	errPage   int
+	requested []int
+}
+
+func (p *fakePager) LoadPage(n int) ([]SearchResult, bool, error) {
+	p.requested = append(p.requested, n)
+	if p.budget != nil {
+		if err := p.budget.SpendResultPage(); err != nil {
+			return nil, false, err
+		}
+	}
+	if p.err != nil && n == p.errPage {
+		return nil, false, p.err
+	}
+	if n > len(p.pages) {
+		return nil, false, nil
+	}
+	return p.pages[n-1], p.endless || n < len(p.pages), nil
+}
+
+// page returns count results for page n with URLs unique to that page.
+func page(n, count int) []SearchResult {
+	var results []SearchResult
+	for i := 1; i <= count; i++ {
+		results = append(results, SearchResult{ProfileURL: fmt.Sprintf("https://www.linkedin.com/in/p%d-%d/", n, i), Page: n, Position: i})
+	}
+	return results
+}
+
+func TestCollectPagesStopsAtLastPage(t *testing.T) {
+	pager := &fakePager{pages: [][]SearchResult{page(1, 10), page(2, 10), page(3, 4)}}
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 10}, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 24 {
+		t.Errorf("got %d results, want 24", len(results))
+	}
+	if len(pager.requested) != 3 {
+		t.Errorf("requested pages %v, want 1 to 3", pager.requested)
+	}
+}
+
+func TestCollectPagesStopsAtEmptyPage(t *testing.T) {
+	pager := &fakePager{pages: [][]SearchResult{page(1, 10), {}}, endless: true}
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 10}, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 10 {
+		t.Errorf("got %d results, want 10", len(results))
+	}
+	if len(pager.requested) != 2 {
+		t.Errorf("requested pages %v, want 1 and 2", pager.requested)
+	}
+}
+
+func TestCollectPagesStopsAtPageLimit(t *testing.T) {
+	pager := &fakePager{pages: [][]SearchResult{page(1, 10), page(2, 10), page(3, 10)}}
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 2}, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 20 || len(pager.requested) != 2 {
+		t.Errorf("got %d results from pages %v, want 20 from pages 1 and 2", len(results), pager.requested)
+	}
+}
+
+func TestCollectPagesCapsAtMaxSearchPages(t *testing.T) {
+	var pages [][]SearchResult
+	for n := 1; n <= maxSearchPages+5; n++ {
+		pages = append(pages, page(n, 1))
+	}
+	pager := &fakePager{pages: pages}
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 500}, map[string]bool{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pager.requested) != maxSearchPages || len(results) != maxSearchPages {
+		t.Errorf("loaded %d pages with %d results, want %d", len(pager.requested), len(results), maxSearchPages)
+	}
+}
+
+func TestCollectPagesSkipsVisitedProfiles(t *testing.T) {
+	first := page(1, 3)
+	pager := &fakePager{pages: [][]SearchResult{first, append(page(2, 1), first[0])}}
+	visited := map[string]bool{first[1].ProfileURL: true}
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 10}, visited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Errorf("got %d results, want 3 (one already visited, one repeated)", len(results))
+	}
+	if len(visited) != 4 {
+		t.Errorf("visited has %d profiles, want 4", len(visited))
+	}
+}
+
+func TestCollectPagesStopAtKnownPage(t *testing.T) {
+	known := page(2, 10)
+	visited := make(map[string]bool)
+	for _, result := range known {
+		visited[result.ProfileURL] = true
+	}
+	pager := &fakePager{pages: [][]SearchResult{page(1, 10), known, page(3, 10)}}
+
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 10, StopAtKnownPage: true}, visited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 10 || len(pager.requested) != 2 {
+		t.Errorf("got %d results from pages %v, want 10 from pages 1 and 2", len(results), pager.requested)
+	}
+
+	// Without StopAtKnownPage the known page is only skipped.
+	pager.requested = nil
+	for _, result := range results {
+		delete(visited, result.ProfileURL)
+	}
+	results, err = collectPages(pager, SearchUserCriteria{PageLimit: 10}, visited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 20 || len(pager.requested) != 3 {
+		t.Errorf("got %d results from pages %v, want 20 from pages 1 to 3", len(results), pager.requested)
+	}
+}
+
+func TestCollectPagesStopsWhenBudgetIsExhausted(t *testing.T) {
+	pager := &fakePager{pages: [][]SearchResult{page(1, 10), page(2, 10), page(3, 10)}, budget: budget.New(2, 0)}
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 10}, map[string]bool{})
+	if err != nil {
+		t.Fatalf("budget exhaustion should end the search without an error, got %v", err)
+	}
+	if len(results) != 20 {
+		t.Errorf("got %d results, want the 20 of the pages within budget", len(results))
+	}
+	if usage := pager.budget.Usage(); usage.ResultPages != 2 {
+		t.Errorf("spent %d result pages, want 2", usage.ResultPages)
+	}
+}
+
+func TestCollectPagesReturnsLoadErrors(t *testing.T) {
+	loadErr := errors.New("navigation failed")
+	pager := &fakePager{pages: [][]SearchResult{page(1, 10), page(2, 10)}, err: loadErr, errPage: 2}
+	results, err := collectPages(pager, SearchUserCriteria{PageLimit: 10}, map[string]bool{})
+	if !errors.Is(err, loadErr) {
+		t.Errorf("got error %v, want it to wrap %v", err, loadErr)
+	}
+	if len(results) != 10 {
+		t.Errorf("got %d results, want the 10 loaded before the error", len(results))
+	}
+}
+
+func TestWithPageNumber(t *testing.T) {
+	tests := []struct {
+		url  string
+		n    int
+		want string
+	}{
+		{"https://www.linkedin.com/search/results/people/?keywords=go", 1, "https://www.linkedin.com/search/results/people/?keywords=go"},
+		{"https://www.linkedin.com/search/results/people/?keywords=go", 3, "https://www.linkedin.com/search/results/people/?keywords=go&page=3"},
+		{"https://www.linkedin.com/search/results/people/?keywords=go&page=3", 1, "https://www.linkedin.com/search/results/people/?keywords=go"},
+	}
+	for _, tt := range tests {
+		got, err := withPageNumber(tt.url, tt.n)
+		if err != nil || got != tt.want {
+			t.Errorf("withPageNumber(%q, %d) = %q, %v; want %q", tt.url, tt.n, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// peopleSearchPath is the path of LinkedIn's people search.
+const peopleSearchPath = "/search/results/people/"
+
+// peopleSearchURL is the base URL of LinkedIn's people search.
+const peopleSearchURL = "https://www.linkedin.com" + peopleSearchPath
+
+// Facet is the URL parameter of a people-search filter whose values are IDs.
+type Facet string
+
+const (
+	FacetCurrentCompany  Facet = "currentCompany"
+	FacetPastCompany     Facet = "pastCompany"
+	FacetGeo             Facet = "geoUrn"
+	FacetIndustry        Facet = "industry"
+	FacetSchool          Facet = "schoolFilter"
+	FacetNetwork         Facet = "network"
+	FacetProfileLanguage Facet = "profileLanguage"
+)
+
+// networkCodes maps a connection degree to the network facet value.
+var networkCodes = map[int]string{1: "F", 2: "S", 3: "O"}
+
+// FacetTable resolves human-readable facet values ("Google", "San Francisco
+// Bay Area") to the numeric IDs the search URL requires. It starts from a
+// small built-in table and can be extended from a JSON file that acts as a
+// local cache of known IDs.
+type FacetTable struct {
+	ids map[Facet]map[string]string
+	// Lookup, if set, finds the IDs of names missing from the table. Found IDs
+	// are added to the table and, when CacheFile is set, saved there, so each
+	// name is only looked up once.
+	Lookup    FacetLookup
+	CacheFile string
+}
+
+// FacetLookup finds the ID of a facet value name, e.g. through the search
+// typeahead. It returns an error if nothing matches.
+type FacetLookup func(facet Facet, name string) (string, error)
+
+// builtinFacetIDs seeds every FacetTable with commonly used IDs.
+var builtinFacetIDs = map[Facet]map[string]string{
+	FacetCurrentCompany: {
+		"google":    "1441",
+		"microsoft": "1035",
+		"linkedin":  "1337",
+		"amazon":    "1586",
+		"apple":     "162479",
+	},
+	FacetGeo: {
+		"united states":          "103644278",
+		"san francisco bay area": "90000084",
+	},
+	FacetIndustry: {
+		"software development":                "4",
+		"it services and it consulting":       "96",
+		"information technology & services":   "96",
+		"information technology and services": "96",
+	},
+	FacetSchool: {
+		"stanford university": "1792",
+	},
+}
+
+// NewFacetTable returns a table holding the built-in IDs. Company IDs apply to
+// both current and past company facets.
+func NewFacetTable() *FacetTable {
+	t := &FacetTable{ids: make(map[Facet]map[string]string)}
+	for facet, ids := range builtinFacetIDs {
+		for name, id := range ids {
+			t.Add(facet, name, id)
+		}
+	}
+	return t
+}
+
+// Add records the ID for a facet value name.
+func (t *FacetTable) Add(facet Facet, name, id string) {
+	facet = companyFacet(facet)
+	if t.ids[facet] == nil {
+		t.ids[facet] = make(map[string]string)
+	}
+	t.ids[facet][normalizeFacetName(name)] = id
+}
+
+// Resolve returns the ID for value. Values that are already numeric IDs (or
+// URNs such as "urn:li:geo:103644278") are returned as-is.
+func (t *FacetTable) Resolve(facet Facet, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if i := strings.LastIndex(value, ":"); strings.HasPrefix(value, "urn:li:") && i >= 0 {
+		value = value[i+1:]
+	}
+	if isNumericID(value) {
+		return value, nil
+	}
+	if id, ok := t.ids[companyFacet(facet)][normalizeFacetName(value)]; ok {
+		return id, nil
+	}
+	if t.Lookup == nil {
+		return "", fmt.Errorf("unknown %s %q: use its numeric ID or add it to the facet table file", facet, value)
+	}
+	id, err := t.Lookup(facet, value)
+	if err != nil {
+		return "", fmt.Errorf("unknown %s %q: %w", facet, value, err)
+	}
+	if !isNumericID(id) {
+		return "", fmt.Errorf("unknown %s %q: lookup returned %q, not a numeric ID", facet, value, id)
+	}
+	log.Printf("Resolved %s %q to ID %s", facet, value, id)
+	t.Add(facet, value, id)
+	if t.CacheFile != "" {
+		if err := t.SaveFile(t.CacheFile); err != nil {
+			// The ID is still usable for this run; it is just looked up again next time
+			log.Printf("Warning: failed to cache facet ID: %v", err)
+		}
+	}
+	return id, nil
+}
+
+// LoadFile merges IDs from a JSON file shaped like
+// {"geoUrn": {"Greater Boston": "90000007"}}. A missing file is not an error.
+func (t *FacetTable) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read facet table: %w", err)
+	}
+	var entries map[Facet]map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse facet table %s: %w", path, err)
+	}
+	for facet, ids := range entries {
+		for name, id := range ids {
+			t.Add(facet, name, id)
+		}
+	}
+	return nil
+}
+
+// SaveFile writes the whole table, including anything added at runtime, as JSON.
+func (t *FacetTable) SaveFile(path string) error {
+	data, err := json.MarshalIndent(t.ids, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode facet table: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write facet table: %w", err)
+	}
+	return nil
+}
+
+// BuildSearchURL constructs a people-search URL for criteria, resolving facet
+// names through table. Parameters are emitted in sorted order, so the same
+// criteria always produce the same URL. Every unresolvable value is reported.
+func BuildSearchURL(criteria SearchUserCriteria, table *FacetTable) (string, error) {
+	params := url.Values{}
+	var problems []string
+
+	if len(criteria.Keywords) > 0 {
+		params.Set("keywords", strings.Join(criteria.Keywords, " "))
+	}
+	if criteria.JobTitle != "" {
+		params.Set("titleFreeText", criteria.JobTitle)
+	}
+
+	addFacet := func(facet Facet, values []string) {
+		var ids []string
+		for _, value := range values {
+			id, err := table.Resolve(facet, value)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) > 0 {
+			params.Set(string(facet), facetList(ids))
+		}
+	}
+	addFacet(FacetCurrentCompany, criteria.CurrentCompanies)
+	addFacet(FacetPastCompany, criteria.PastCompanies)
+	addFacet(FacetGeo, criteria.Locations)
+	addFacet(FacetIndustry, criteria.Industries)
+	addFacet(FacetSchool, criteria.Schools)
+
+	if len(criteria.NetworkDegrees) > 0 {
+		var codes []string
+		for _, degree := range criteria.NetworkDegrees {
+			code, ok := networkCodes[degree]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("invalid network degree %d: use 1, 2 or 3", degree))
+				continue
+			}
+			codes = append(codes, code)
+		}
+		if len(codes) > 0 {
+			params.Set(string(FacetNetwork), facetList(codes))
+		}
+	}
+	if len(criteria.ProfileLanguages) > 0 {
+		params.Set(string(FacetProfileLanguage), facetList(criteria.ProfileLanguages))
+	}
+	for key, values := range criteria.ExtraParams {
+		if params.Has(key) {
+			problems = append(problems, fmt.Sprintf("extra parameter %q conflicts with a modelled criterion", key))
+			continue
+		}
+		params[key] = append([]string(nil), values...)
+	}
+
+	if len(problems) > 0 {
+		return "", fmt.Errorf("invalid search criteria: %s", strings.Join(problems, "; "))
+	}
+	if len(params) == 0 {
+		return "", fmt.Errorf("invalid search criteria: no keywords, title or facets given")
+	}
+
+	params.Set("origin", "FACETED_SEARCH")
+	return peopleSearchURL + "?" + params.Encode(), nil
+}
+
+// ignoredSearchParams are session or UI-state parameters that do not affect
+// which people a search returns.
+var ignoredSearchParams = map[string]bool{
+	"origin":                 true,
+	"sid":                    true,
+	"page":                   true,
+	"spellCorrectionEnabled": true,
+	"searchId":               true,
+	"prevSearchId":           true,
+	"trk":                    true,
+}
+
+// ParseSearchURL turns a people-search URL copied from the browser into
+// criteria. Building a URL from the result with BuildSearchURL reproduces the
+// same search: facet IDs are kept as IDs, parameters this package does not
+// model are preserved in ExtraParams, and only session noise such as origin,
+// sid and page is dropped.
+func ParseSearchURL(rawURL string) (SearchUserCriteria, error) {
+	var criteria SearchUserCriteria
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return criteria, fmt.Errorf("invalid search URL: %w", err)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") || strings.TrimSuffix(u.Path, "/") != strings.TrimSuffix(peopleSearchPath, "/") {
+		return criteria, fmt.Errorf("not a LinkedIn people-search URL: %s", rawURL)
+	}
+
+	var problems []string
+	for key, values := range u.Query() {
+		if ignoredSearchParams[key] || len(values) == 0 {
+			continue
+		}
+		value := values[0]
+		switch key {
+		case "keywords":
+			criteria.Keywords = []string{value}
+		case "titleFreeText":
+			criteria.JobTitle = value
+		case string(FacetCurrentCompany):
+			criteria.CurrentCompanies = parseFacetList(value)
+		case string(FacetPastCompany):
+			criteria.PastCompanies = parseFacetList(value)
+		case string(FacetGeo):
+			criteria.Locations = parseFacetList(value)
+		case string(FacetIndustry):
+			criteria.Industries = parseFacetList(value)
+		case string(FacetSchool):
+			criteria.Schools = parseFacetList(value)
+		case string(FacetProfileLanguage):
+			criteria.ProfileLanguages = parseFacetList(value)
+		case string(FacetNetwork):
+			for _, code := range parseFacetList(value) {
+				degree, ok := networkDegree(code)
+				if !ok {
+					problems = append(problems, fmt.Sprintf("unknown network value %q", code))
+					continue
+				}
+				criteria.NetworkDegrees = append(criteria.NetworkDegrees, degree)
+			}
+		default:
+			if criteria.ExtraParams == nil {
+				criteria.ExtraParams = url.Values{}
+			}
+			criteria.ExtraParams[key] = values
+		}
+	}
+	if len(problems) > 0 {
+		return criteria, fmt.Errorf("invalid search URL: %s", strings.Join(problems, "; "))
+	}
+	return criteria, nil
+}
+
+// parseFacetList reads a facet value written as ["a","b"] or as a plain
+// comma-separated list.
+func parseFacetList(value string) []string {
+	var values []string
+	if err := json.Unmarshal([]byte(value), &values); err == nil {
+		return values
+	}
+	for _, v := range strings.Split(strings.Trim(value, "[]"), ",") {
+		if v = strings.Trim(strings.TrimSpace(v), `"`); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// networkDegree maps a network facet code back to its connection degree.
+func networkDegree(code string) (int, bool) {
+	for degree, c := range networkCodes {
+		if c == code {
+			return degree, true
+		}
+	}
+	return 0, false
+}
+
+// facetList formats facet values the way the search UI does: ["1441","1035"].
+func facetList(values []string) string {
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// companyFacet makes current and past company share one set of IDs.
+func companyFacet(facet Facet) Facet {
+	if facet == FacetPastCompany {
+		return FacetCurrentCompany
+	}
+	return facet
+}
+
+// normalizeFacetName lower-cases and collapses whitespace for lookups.
+func normalizeFacetName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// isNumericID reports whether s is a non-empty string of digits.
+func isNumericID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package search
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSearchURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria SearchUserCriteria
+		want     string
+	}{
+		{
+			name:     "keywords",
+			criteria: SearchUserCriteria{Keywords: []string{"golang", "developer"}},
+			want:     peopleSearchURL + "?keywords=golang+developer&origin=FACETED_SEARCH",
+		},
+		{
+			name:     "title",
+			criteria: SearchUserCriteria{JobTitle: "Engineering Manager"},
+			want:     peopleSearchURL + "?origin=FACETED_SEARCH&titleFreeText=Engineering+Manager",
+		},
+		{
+			name:     "current companies by name and ID",
+			criteria: SearchUserCriteria{CurrentCompanies: []string{"Google", "1035"}},
+			want:     peopleSearchURL + "?currentCompany=%5B%221441%22%2C%221035%22%5D&origin=FACETED_SEARCH",
+		},
+		{
+			name:     "past companies share the company IDs",
+			criteria: SearchUserCriteria{PastCompanies: []string{"amazon"}},
+			want:     peopleSearchURL + "?origin=FACETED_SEARCH&pastCompany=%5B%221586%22%5D",
+		},
+		{
+			name:     "location names are normalized",
+			criteria: SearchUserCriteria{Locations: []string{"  San Francisco   BAY Area "}},
+			want:     peopleSearchURL + "?geoUrn=%5B%2290000084%22%5D&origin=FACETED_SEARCH",
+		},
+		{
+			name:     "industry",
+			criteria: SearchUserCriteria{Industries: []string{"Software Development"}},
+			want:     peopleSearchURL + "?industry=%5B%224%22%5D&origin=FACETED_SEARCH",
+		},
+		{
+			name:     "school by URN",
+			criteria: SearchUserCriteria{Schools: []string{"urn:li:school:1792"}},
+			want:     peopleSearchURL + "?origin=FACETED_SEARCH&schoolFilter=%5B%221792%22%5D",
+		},
+		{
+			name:     "network degrees",
+			criteria: SearchUserCriteria{NetworkDegrees: []int{1, 2, 3}},
+			want:     peopleSearchURL + "?network=%5B%22F%22%2C%22S%22%2C%22O%22%5D&origin=FACETED_SEARCH",
+		},
+		{
+			name:     "profile languages",
+			criteria: SearchUserCriteria{ProfileLanguages: []string{"en", "de"}},
+			want:     peopleSearchURL + "?origin=FACETED_SEARCH&profileLanguage=%5B%22en%22%2C%22de%22%5D",
+		},
+		{
+			name:     "extra parameters",
+			criteria: SearchUserCriteria{Keywords: []string{"go"}, ExtraParams: url.Values{"serviceCategory": {"602"}}},
+			want:     peopleSearchURL + "?keywords=go&origin=FACETED_SEARCH&serviceCategory=602",
+		},
+		{
+			name: "every facet",
+			criteria: SearchUserCriteria{
+				JobTitle:         "CTO",
+				Keywords:         []string{"saas"},
+				CurrentCompanies: []string{"Microsoft"},
+				PastCompanies:    []string{"LinkedIn"},
+				Locations:        []string{"United States"},
+				NetworkDegrees:   []int{2},
+				Industries:       []string{"IT Services and IT Consulting"},
+				Schools:          []string{"Stanford University"},
+				ProfileLanguages: []string{"en"},
+			},
+			want: peopleSearchURL + "?currentCompany=%5B%221035%22%5D&geoUrn=%5B%22103644278%22%5D&industry=%5B%2296%22%5D" +
+				"&keywords=saas&network=%5B%22S%22%5D&origin=FACETED_SEARCH&pastCompany=%5B%221337%22%5D" +
+				"&profileLanguage=%5B%22en%22%5D&schoolFilter=%5B%221792%22%5D&titleFreeText=CTO",
+		},
+	}
+	for _, tt := range tests {
+		got, err := BuildSearchURL(tt.criteria, NewFacetTable())
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildSearchURLReportsEveryProblem(t *testing.T) {
+	criteria := SearchUserCriteria{
+		Keywords:         []string{"go"},
+		CurrentCompanies: []string{"Initech", "Google"},
+		Locations:        []string{"Atlantis"},
+		NetworkDegrees:   []int{4},
+		ExtraParams:      url.Values{"keywords": {"rust"}},
+	}
+	got, err := BuildSearchURL(criteria, NewFacetTable())
+	if err == nil {
+		t.Fatalf("got %s, want an error", got)
+	}
+	for _, problem := range []string{
+		`unknown currentCompany "Initech"`,
+		`unknown geoUrn "Atlantis"`,
+		"invalid network degree 4",
+		`extra parameter "keywords" conflicts`,
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("error %q does not mention %q", err, problem)
+		}
+	}
+	if got != "" {
+		t.Errorf("got URL %s along with the error", got)
+	}
+
+	if _, err := BuildSearchURL(SearchUserCriteria{}, NewFacetTable()); err == nil {
+		t.Error("empty criteria: want an error")
+	}
+}
+
+func TestFacetTableLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facets.json")
+	if err := os.WriteFile(path, []byte(`{"geoUrn": {"Greater Boston": "90000007"}, "pastCompany": {"Initech": "42"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	table := NewFacetTable()
+	if err := table.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := BuildSearchURL(SearchUserCriteria{Locations: []string{"greater boston"}, CurrentCompanies: []string{"initech"}}, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := peopleSearchURL + "?currentCompany=%5B%2242%22%5D&geoUrn=%5B%2290000007%22%5D&origin=FACETED_SEARCH"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if err := table.LoadFile(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("missing facet table file: %v", err)
+	}
+}
+
+func TestFacetTableLookupIsCached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facets.json")
+	var lookups []string
+	table := NewFacetTable()
+	table.CacheFile = path
+	table.Lookup = func(facet Facet, name string) (string, error) {
+		lookups = append(lookups, string(facet)+"/"+name)
+		switch name {
+		case "Initech":
+			return "42", nil
+		case "Hooli":
+			return "urn:li:company:7", nil
+		}
+		return "", errors.New("no match found")
+	}
+
+	for i := 0; i < 2; i++ {
+		if id, err := table.Resolve(FacetPastCompany, "Initech"); err != nil || id != "42" {
+			t.Fatalf("Resolve(Initech) = %q, %v; want 42", id, err)
+		}
+	}
+	if id, err := table.Resolve(FacetCurrentCompany, "initech"); err != nil || id != "42" {
+		t.Errorf("past and current company share looked-up IDs: got %q, %v", id, err)
+	}
+	if id, err := table.Resolve(FacetGeo, "Google"); err == nil {
+		t.Errorf("Resolve(geoUrn Google) = %q, want the lookup error", id)
+	}
+	if _, err := table.Resolve(FacetCurrentCompany, "Hooli"); err == nil || !strings.Contains(err.Error(), "not a numeric ID") {
+		t.Errorf("non-numeric lookup result: got error %v", err)
+	}
+	if want := []string{"pastCompany/Initech", "geoUrn/Google", "currentCompany/Hooli"}; strings.Join(lookups, ",") != strings.Join(want, ",") {
+		t.Errorf("lookups = %v, want %v", lookups, want)
+	}
+
+	// A new table loading the cache file knows the ID without a lookup.
+	cached := NewFacetTable()
+	if err := cached.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := cached.Resolve(FacetCurrentCompany, "INITECH"); err != nil || id != "42" {
+		t.Errorf("Resolve from cache file = %q, %v; want 42", id, err)
+	}
+}
+
+func TestFirstURNID(t *testing.T) {
+	response := `{"elements":[{"hitInfo":{"com.linkedin.voyager.typeahead.TypeaheadCompany":{"id":"1441",` +
+		`"company":{"entityUrn":"urn:li:fs_miniCompany:1441","name":"Google"}}}},` +
+		`{"hitInfo":{"company":{"entityUrn":"urn:li:fs_miniCompany:3000"}}}]}`
+	if id, err := firstURNID(typeaheadFacets[FacetCurrentCompany].urn, response); err != nil || id != "1441" {
+		t.Errorf("company = %q, %v; want 1441", id, err)
+	}
+	if id, err := firstURNID(typeaheadFacets[FacetGeo].urn, `{"elements":[{"targetUrn":"urn:li:fs_geo:90000007"}]}`); err != nil || id != "90000007" {
+		t.Errorf("geo = %q, %v; want 90000007", id, err)
+	}
+	if id, err := firstURNID(typeaheadFacets[FacetSchool].urn, response); err == nil {
+		t.Errorf("school in a company response = %q, want an error", id)
+	}
+}
+
+func TestParseSearchURLRoundTrip(t *testing.T) {
+	pasted := "https://www.linkedin.com/search/results/people/?currentCompany=%5B%221441%22%5D&geoUrn=%5B%22103644278%22%5D" +
+		"&keywords=platform%20engineer&network=%5B%22F%22%2C%22S%22%5D&origin=FACETED_SEARCH&page=4&serviceCategory=602&sid=abc"
+	criteria, err := ParseSearchURL(pasted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := BuildSearchURL(criteria, NewFacetTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := peopleSearchURL + "?currentCompany=%5B%221441%22%5D&geoUrn=%5B%22103644278%22%5D" +
+		"&keywords=platform+engineer&network=%5B%22F%22%2C%22S%22%5D&origin=FACETED_SEARCH&serviceCategory=602"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestParseSearchURLHosts(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://www.linkedin.com/search/results/people/?keywords=go", true},
+		{"https://linkedin.com/search/results/people?keywords=go", true},
+		{"https://WWW.LinkedIn.com/search/results/people/?keywords=go", true},
+		{"https://evil-linkedin.com/search/results/people/?keywords=go", false},
+		{"https://www.linkedin.com.evil.com/search/results/people/?keywords=go", false},
+		{"https://www.linkedin.com/search/results/companies/?keywords=go", false},
+	}
+	for _, tt := range tests {
+		_, err := ParseSearchURL(tt.url)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseSearchURL(%q) error = %v, want ok = %v", tt.url, err, tt.ok)
+		}
+	}
+}
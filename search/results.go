@@ -0,0 +1,206 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// PrimaryAction is the main button shown on a search result card.
+type PrimaryAction string
+
+const (
+	ActionConnect PrimaryAction = "connect"
+	ActionFollow  PrimaryAction = "follow"
+	ActionMessage PrimaryAction = "message"
+	ActionUnknown PrimaryAction = ""
+)
+
+// SearchResult is one person from a people-search result page.
+type SearchResult struct {
+	ProfileURL string
+	Name       string
+	Headline   string
+	Location   string
+	// ConnectionDegree is 1, 2 or 3 (3rd+), or 0 if not shown (e.g. out of network).
+	ConnectionDegree  int
+	MutualConnections int
+	PrimaryAction     PrimaryAction
+	Page              int // 1-based result page the profile appeared on
+	Position          int // 1-based position on that page
+}
+
+// rawResult is what extractResultsJS returns for each result card.
+type rawResult struct {
+	Href     string `json:"href"`
+	Name     string `json:"name"`
+	Headline string `json:"headline"`
+	Location string `json:"location"`
+	Degree   string `json:"degree"`
+	Mutual   string `json:"mutual"`
+	Action   string `json:"action"`
+}
+
+// resultContainerSelector matches one result card on the people-search page.
+const resultContainerSelector = ".reusable-search__result-container"
+
+// extractResultsJS reads the visible fields of every result card. Parsing of
+// the raw text happens in Go so it can be unit tested without a browser.
+const extractResultsJS = `(selector) => {
+	const text = (root, sel) => {
+		const el = root.querySelector(sel);
+		return el ? el.innerText.trim() : '';
+	};
+	return JSON.stringify(Array.from(document.querySelectorAll(selector)).map(card => {
+		const link = card.querySelector('a.app-aware-link[href*="/in/"]');
+		const button = card.querySelector('.entity-result__actions button');
+		return {
+			href: link ? link.href : '',
+			name: text(card, '.entity-result__title-text a span[aria-hidden="true"]') || text(card, '.entity-result__title-text a'),
+			headline: text(card, '.entity-result__primary-subtitle'),
+			location: text(card, '.entity-result__secondary-subtitle'),
+			degree: text(card, '.entity-result__badge-text span[aria-hidden="true"]') || text(card, '.entity-result__badge-text'),
+			mutual: text(card, '.entity-result__simple-insight-text'),
+			action: button ? (button.innerText.trim() || button.getAttribute('aria-label') || '') : '',
+		};
+	}));
+}`
+
+// parseResults extracts the result cards currently rendered on page.
+// Cards that do not link to a member profile (ads, "LinkedIn Member"
+// placeholders) are skipped.
+func parseResults(page *rod.Page, pageNumber int) ([]SearchResult, error) {
+	obj, err := page.Eval(extractResultsJS, resultContainerSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract result cards: %w", err)
+	}
+	var raws []rawResult
+	if err := json.Unmarshal([]byte(obj.Value.Str()), &raws); err != nil {
+		return nil, fmt.Errorf("failed to decode result cards: %w", err)
+	}
+	return buildResults(raws, pageNumber), nil
+}
+
+// buildResults turns raw card fields into SearchResults, numbering positions
+// among the cards that link to a profile.
+func buildResults(raws []rawResult, pageNumber int) []SearchResult {
+	var results []SearchResult
+	for _, raw := range raws {
+		profileURL, ok := NormalizeProfileURL(raw.Href)
+		if !ok {
+			continue
+		}
+		results = append(results, SearchResult{
+			ProfileURL:        profileURL,
+			Name:              strings.TrimSpace(raw.Name),
+			Headline:          strings.TrimSpace(raw.Headline),
+			Location:          strings.TrimSpace(raw.Location),
+			ConnectionDegree:  parseConnectionDegree(raw.Degree),
+			MutualConnections: parseMutualConnections(raw.Mutual),
+			PrimaryAction:     parsePrimaryAction(raw.Action),
+			Page:              pageNumber,
+			Position:          len(results) + 1,
+		})
+	}
+	return results
+}
+
+// NormalizeProfileURL reduces a profile link to https://www.linkedin.com/in/<id>/,
+// dropping tracking query parameters, so the same person always has the same
+// URL. Legacy /pub/<name>/<a>/<b>/<c>/ links keep their full path, since the
+// name alone does not identify the profile. Links without a scheme
+// ("linkedin.com/in/jane") are accepted. It reports false for anything that
+// is not a LinkedIn member profile.
+func NormalizeProfileURL(href string) (string, bool) {
+	href = strings.TrimSpace(href)
+	if !strings.Contains(href, "://") {
+		href = "https://" + strings.TrimPrefix(href, "//")
+	}
+	parsedURL, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	if host := strings.ToLower(parsedURL.Hostname()); host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+		return "", false
+	}
+	// Vanity names are case-insensitive, so lower-case them for deduplication.
+	segments := strings.Split(strings.ToLower(strings.Trim(parsedURL.Path, "/")), "/")
+	if len(segments) >= 2 && segments[0] == "in" && segments[1] != "" {
+		return fmt.Sprintf("https://www.linkedin.com/in/%s/", segments[1]), true
+	}
+	if len(segments) >= 5 && segments[0] == "pub" && !hasEmptySegment(segments[1:5]) {
+		return fmt.Sprintf("https://www.linkedin.com/%s/", strings.Join(segments[:5], "/")), true
+	}
+	return "", false
+}
+
+// hasEmptySegment reports whether any path segment is empty, as in "/pub/jane//1/2".
+func hasEmptySegment(segments []string) bool {
+	for _, segment := range segments {
+		if segment == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// degreePattern finds the degree in badge text such as "• 2nd" or "3rd+ degree connection".
+var degreePattern = regexp.MustCompile(`([123])(?:st|nd|rd)`)
+
+// parseConnectionDegree returns 1, 2 or 3 from the badge text, or 0 if absent.
+func parseConnectionDegree(badge string) int {
+	match := degreePattern.FindStringSubmatch(badge)
+	if match == nil {
+		return 0
+	}
+	degree, _ := strconv.Atoi(match[1])
+	return degree
+}
+
+// otherMutualPattern matches "... and 12 other mutual connections".
+var otherMutualPattern = regexp.MustCompile(`(\d[\d,]*)\s+other mutual connection`)
+
+// countMutualPattern matches "12 mutual connections".
+var countMutualPattern = regexp.MustCompile(`(\d[\d,]*)\s+mutual connection`)
+
+// parseMutualConnections counts mutual connections from insight text such as
+// "Jane Doe is a mutual connection", "Jane Doe and John Roe are mutual
+// connections" or "Jane Doe and 12 other mutual connections".
+func parseMutualConnections(insight string) int {
+	insight = strings.ToLower(strings.TrimSpace(insight))
+	if !strings.Contains(insight, "mutual connection") {
+		return 0
+	}
+	if match := otherMutualPattern.FindStringSubmatch(insight); match != nil {
+		n, _ := strconv.Atoi(strings.ReplaceAll(match[1], ",", ""))
+		return n + 1 // the named connection plus the others
+	}
+	if match := countMutualPattern.FindStringSubmatch(insight); match != nil {
+		n, _ := strconv.Atoi(strings.ReplaceAll(match[1], ",", ""))
+		return n
+	}
+	if strings.Contains(insight, " and ") {
+		return 2
+	}
+	return 1
+}
+
+// parsePrimaryAction maps the card's main button label to a PrimaryAction.
+func parsePrimaryAction(label string) PrimaryAction {
+	label = strings.ToLower(label)
+	switch {
+	case strings.Contains(label, "connect") || strings.HasPrefix(label, "invite"):
+		return ActionConnect
+	case strings.Contains(label, "follow"):
+		return ActionFollow
+	case strings.Contains(label, "message"):
+		return ActionMessage
+	default:
+		return ActionUnknown
+	}
+}
@@ -0,0 +1,199 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// loadRawResults reads the card fields extractResultsJS returns for
+// testdata/results_page.html.
+func loadRawResults(t *testing.T) []rawResult {
+	t.Helper()
+	data, err := os.ReadFile("testdata/results_page.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raws []rawResult
+	if err := json.Unmarshal(data, &raws); err != nil {
+		t.Fatal(err)
+	}
+	return raws
+}
+
+func TestBuildResults(t *testing.T) {
+	got := buildResults(loadRawResults(t), 3)
+	want := []SearchResult{
+		{
+			ProfileURL: "https://www.linkedin.com/in/jane-doe-123/", Name: "Jane Doe", Headline: "Staff Engineer at Acme", Location: "San Francisco Bay Area",
+			ConnectionDegree: 2, MutualConnections: 13, PrimaryAction: ActionConnect, Page: 3, Position: 1,
+		},
+		{
+			ProfileURL: "https://www.linkedin.com/in/ravi-kumar/", Name: "Ravi Kumar", Headline: "Product Manager", Location: "Bengaluru, Karnataka, India",
+			ConnectionDegree: 1, PrimaryAction: ActionMessage, Page: 3, Position: 2,
+		},
+		{
+			ProfileURL: "https://www.linkedin.com/in/ana-garcia-5b1a2c3d/", Name: "Ana García", Headline: "Ingeniera de Software", Location: "Madrid",
+			ConnectionDegree: 3, MutualConnections: 1, PrimaryAction: ActionFollow, Page: 3, Position: 3,
+		},
+		{
+			ProfileURL: "https://www.linkedin.com/in/li-xiaolong/", Name: "李小龍", Headline: "Martial Artist", Location: "Hong Kong SAR",
+			ConnectionDegree: 2, MutualConnections: 2, PrimaryAction: ActionConnect, Page: 3, Position: 4,
+		},
+		{
+			ProfileURL: "https://www.linkedin.com/in/sam-out-of-network/", Name: "Sam Taylor", Headline: "Founder", Location: "London",
+			PrimaryAction: ActionUnknown, Page: 3, Position: 5,
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("result %d:\n got %+v\nwant %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestBuildResultsTrimsFields(t *testing.T) {
+	got := buildResults([]rawResult{{Href: "linkedin.com/in/jane", Name: " Jane ", Headline: "\tCTO\n", Location: " Berlin "}}, 1)
+	if len(got) != 1 || got[0].Name != "Jane" || got[0].Headline != "CTO" || got[0].Location != "Berlin" {
+		t.Errorf("got %+v, want trimmed fields", got)
+	}
+}
+
+func TestNormalizeProfileURL(t *testing.T) {
+	tests := []struct {
+		href string
+		want string
+		ok   bool
+	}{
+		{"https://www.linkedin.com/in/jane-doe/", "https://www.linkedin.com/in/jane-doe/", true},
+		{"https://www.linkedin.com/in/Jane-Doe?trk=public_profile&miniProfileUrn=x", "https://www.linkedin.com/in/jane-doe/", true},
+		{"https://de.linkedin.com/in/jane-doe/details/experience/", "https://www.linkedin.com/in/jane-doe/", true},
+		{"linkedin.com/in/jane-doe", "https://www.linkedin.com/in/jane-doe/", true},
+		{"//www.linkedin.com/in/jane-doe", "https://www.linkedin.com/in/jane-doe/", true},
+		{"  https://LINKEDIN.COM/in/jane-doe  ", "https://www.linkedin.com/in/jane-doe/", true},
+		{"https://www.linkedin.com/pub/jane-doe/1/2/3", "https://www.linkedin.com/pub/jane-doe/1/2/3/", true},
+		{"https://uk.linkedin.com/pub/Jane-Doe/1/2/3/?trk=x", "https://www.linkedin.com/pub/jane-doe/1/2/3/", true},
+		{"https://www.linkedin.com/in/%E6%9D%8E%E5%B0%8F%E9%BE%8D", "https://www.linkedin.com/in/李小龍/", true},
+		{"https://www.linkedin.com/pub/jane-doe/", "", false},
+		{"https://www.linkedin.com/pub/jane-doe//2/3", "", false},
+		{"https://www.linkedin.com/company/acme/", "", false},
+		{"https://www.linkedin.com/in/", "", false},
+		{"https://evil-linkedin.com/in/jane-doe", "", false},
+		{"https://www.linkedin.com.evil.com/in/jane-doe", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeProfileURL(tt.href)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeProfileURL(%q) = %q, %v; want %q, %v", tt.href, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseConnectionDegree(t *testing.T) {
+	tests := []struct {
+		badge string
+		want  int
+	}{
+		{"• 1st", 1},
+		{"• 2nd", 2},
+		{"• 3rd+", 3},
+		{"3rd+ degree connection", 3},
+		{"", 0},
+		{"Out of network", 0},
+	}
+	for _, tt := range tests {
+		if got := parseConnectionDegree(tt.badge); got != tt.want {
+			t.Errorf("parseConnectionDegree(%q) = %d, want %d", tt.badge, got, tt.want)
+		}
+	}
+}
+
+func TestParseMutualConnections(t *testing.T) {
+	tests := []struct {
+		insight string
+		want    int
+	}{
+		{"", 0},
+		{"Jane Doe is a mutual connection", 1},
+		{"Jane Doe and John Roe are mutual connections", 2},
+		{"Jane Doe and 12 other mutual connections", 13},
+		{"Jane Doe and 1 other mutual connection", 2},
+		{"Jane Doe and 1,204 other mutual connections", 1205},
+		{"12 mutual connections", 12},
+		{"Follows Acme", 0},
+	}
+	for _, tt := range tests {
+		if got := parseMutualConnections(tt.insight); got != tt.want {
+			t.Errorf("parseMutualConnections(%q) = %d, want %d", tt.insight, got, tt.want)
+		}
+	}
+}
+
+func TestParsePrimaryAction(t *testing.T) {
+	tests := []struct {
+		label string
+		want  PrimaryAction
+	}{
+		{"Connect", ActionConnect},
+		{"Invite Jane Doe to connect", ActionConnect},
+		{"Follow", ActionFollow},
+		{"Message", ActionMessage},
+		{"Pending", ActionUnknown},
+		{"", ActionUnknown},
+	}
+	for _, tt := range tests {
+		if got := parsePrimaryAction(tt.label); got != tt.want {
+			t.Errorf("parsePrimaryAction(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+}
+
+// TestExtractResultsJS checks that extractResultsJS reads the saved result page
+// into the fields of results_page.json. It needs a local Chrome or Chromium
+// and is skipped without one.
+func TestExtractResultsJS(t *testing.T) {
+	bin, ok := launcher.LookPath()
+	if !ok {
+		t.Skip("no browser found")
+	}
+	html, err := os.ReadFile("testdata/results_page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	controlURL, err := launcher.New().Bin(bin).Headless(true).Launch()
+	if err != nil {
+		t.Skipf("failed to launch browser: %v", err)
+	}
+	browser := rod.New().ControlURL(controlURL)
+	if err := browser.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer browser.Close()
+
+	page := browser.MustPage("")
+	if err := page.SetDocumentContent(string(html)); err != nil {
+		t.Fatal(err)
+	}
+	obj, err := page.Eval(extractResultsJS, resultContainerSelector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []rawResult
+	if err := json.Unmarshal([]byte(obj.Value.Str()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := loadRawResults(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("extracted cards:\n got %+v\nwant %+v", got, want)
+	}
+	if !hasNextPage(page) {
+		t.Error("hasNextPage = false, want true for an enabled Next button")
+	}
+}
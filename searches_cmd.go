@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"linkedin-automation/config"
+	"linkedin-automation/search"
+	"linkedin-automation/storage"
+)
+
+const searchesUsage = `usage:
+  linkedin-automation [flags] searches add NAME URL
+  linkedin-automation [flags] searches list
+  linkedin-automation [flags] searches remove NAME`
+
+// runSearchesCommand implements the "searches" subcommands, which manage the
+// saved searches stored in the database, and returns the process exit code.
+func runSearchesCommand(loader *config.Loader, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, searchesUsage)
+		return 2
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	store, err := storage.NewStorage(cfg.Storage.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	switch {
+	case args[0] == "add" && len(args) == 3:
+		return addSavedSearch(store, args[1], args[2])
+	case args[0] == "list" && len(args) == 1:
+		return listSavedSearches(store)
+	case args[0] == "remove" && len(args) == 2:
+		deleted, err := store.DeleteSavedSearch(args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !deleted {
+			fmt.Fprintf(os.Stderr, "No saved search named %q.\n", args[1])
+			return 1
+		}
+		fmt.Printf("Removed saved search %q.\n", args[1])
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, searchesUsage)
+		return 2
+	}
+}
+
+// addSavedSearch parses a people-search URL pasted from the browser and
+// stores it, in canonical form, under name.
+func addSavedSearch(store *storage.Storage, name, rawURL string) int {
+	criteria, err := search.ParseSearchURL(rawURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// Rebuilding the URL drops session noise and checks the criteria round-trip.
+	searchURL, err := search.BuildSearchURL(criteria, search.NewFacetTable())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := store.SaveSavedSearch(&storage.SavedSearch{Name: name, SearchURL: searchURL, CreatedAt: time.Now()}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Saved search %q: %s\n", name, searchURL)
+	return 0
+}
+
+// listSavedSearches prints every saved search.
+func listSavedSearches(store *storage.Storage) int {
+	searches, err := store.ListSavedSearches()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(searches) == 0 {
+		fmt.Println("No saved searches.")
+		return 0
+	}
+	for _, s := range searches {
+		lastRun := "never run"
+		if !s.LastRunAt.IsZero() {
+			lastRun = "last run " + s.LastRunAt.Format(time.RFC1123)
+		}
+		fmt.Printf("%s\t%s\t%s\n", s.Name, lastRun, s.SearchURL)
+	}
+	return 0
+}
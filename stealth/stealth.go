@@ -68,8 +68,13 @@ func getPlatform() string {
 
 // RandomDelay introduces a random delay within a specified range.
 func RandomDelay(min, max time.Duration) {
-	delay := min + time.Duration(rand.Int63n(int64(max-min+1)))
-	time.Sleep(delay)
+	time.Sleep(RandomDuration(min, max))
+}
+
+// RandomDuration returns a random duration within a specified range, for
+// callers that need to wait in their own way.
+func RandomDuration(min, max time.Duration) time.Duration {
+	return min + time.Duration(rand.Int63n(int64(max-min+1)))
 }
 
 // SimulateHumanTyping types text with randomized delays and optional typos.
@@ -2,8 +2,12 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // Import for its side effects (driver registration)
@@ -17,15 +21,76 @@ const (
 	StatusAccepted  RequestStatus = "accepted"
 	StatusRejected  RequestStatus = "rejected"
 	StatusSent      RequestStatus = "sent"
+	// Relationships found on the profile rather than created by this tool.
+	// They are recorded so the profile is not visited again, but do not
+	// count towards the daily limit.
+	StatusAlreadyConnected RequestStatus = "already_connected"
+	StatusAlreadyPending   RequestStatus = "already_pending"
+	// StatusWithdrawn marks an invitation withdrawn before it was answered.
+	StatusWithdrawn RequestStatus = "withdrawn"
 )
 
 // SentRequest represents a sent connection request.
 type SentRequest struct {
 	ID         int64
 	ProfileURL string
-	Note       string
+	Note       string // The note as sent, with variables filled in
+	TemplateID string // Version of the note template used, empty without a note
 	SentAt     time.Time
 	Status     RequestStatus
+	Attempts   int // Tries it took, counting retries of transient failures
+}
+
+// ActionKind names an outreach action, for recording failed attempts.
+type ActionKind string
+
+const (
+	ActionInvite   ActionKind = "invite"   // Sending a connection request
+	ActionMessage  ActionKind = "message"  // Sending a follow-up message
+	ActionSearch   ActionKind = "search"   // Loading a page of search results
+	ActionLogin    ActionKind = "login"    // Signing in to LinkedIn
+	ActionWithdraw ActionKind = "withdraw" // Withdrawing a pending connection request
+	ActionVisit    ActionKind = "visit"    // Visiting a profile to record its details
+)
+
+// ActionState is where a queued action stands.
+type ActionState string
+
+const (
+	ActionPending   ActionState = "pending" // Waiting for its scheduled time
+	ActionRunning   ActionState = "running" // Being executed
+	ActionDone      ActionState = "done"
+	ActionFailed    ActionState = "failed"
+	ActionSkipped   ActionState = "skipped"   // No longer needed, e.g. the profile was excluded
+	ActionCancelled ActionState = "cancelled" // Cancelled by hand
+)
+
+// QueuedAction is an outreach action planned for a profile, kept in the
+// database so it survives restarts.
+type QueuedAction struct {
+	ID          int64
+	Campaign    string
+	Kind        ActionKind // ActionInvite, ActionMessage, ActionWithdraw or ActionVisit
+	ProfileURL  string
+	Payload     map[string]string // Kind-specific settings, e.g. a template override
+	ScheduledAt time.Time         // Not executed before this time
+	Attempts    int               // Times the action was started
+	State       ActionState
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// FailedAttempt records an action that could not be confirmed or failed.
+type FailedAttempt struct {
+	ID             int64
+	Action         ActionKind
+	ProfileURL     string // Or the page URL, for searches and logins
+	Reason         string
+	ScreenshotPath string // Empty if no screenshot was taken
+	HTMLPath       string // Sanitized HTML snapshot; empty if none was taken
+	Attempt        int    // Which try of the action failed, starting at 1
+	AttemptedAt    time.Time
 }
 
 // MessageRecord represents a sent follow-up message.
@@ -35,6 +100,105 @@ type MessageRecord struct {
 	Message      string
 	SentAt       time.Time
 	TemplateUsed string
+	Attempts     int // Tries it took, counting retries of transient failures
+}
+
+// SavedSearch is a named people search that can be run repeatedly.
+type SavedSearch struct {
+	ID        int64
+	Name      string
+	SearchURL string // Canonical people-search URL the criteria are parsed from
+	CreatedAt time.Time
+	LastRunAt time.Time // Zero if the search has never been run
+}
+
+// CampaignProfileStatus is where a profile stands in a campaign's queue.
+type CampaignProfileStatus string
+
+const (
+	CampaignProfileQueued    CampaignProfileStatus = "queued"
+	CampaignProfileContacted CampaignProfileStatus = "contacted"
+	CampaignProfileExcluded  CampaignProfileStatus = "excluded"
+)
+
+// CampaignProfile is a profile queued for outreach in a campaign, either found
+// by a search or imported from a list.
+type CampaignProfile struct {
+	ID         int64
+	Campaign   string
+	ProfileURL string
+	Name       string
+	Company    string
+	Headline   string
+	Location   string
+	// ConnectionDegree is 1, 2 or 3 as shown in search results, or 0 if unknown.
+	ConnectionDegree  int
+	MutualConnections int
+	// Score ranks the profile within its campaign; higher scores are contacted first.
+	Score float64
+	// Variables are extra template variables for this profile, e.g. columns of an imported CSV.
+	Variables map[string]string
+	Source    string // "search" or "import"
+	Status    CampaignProfileStatus
+	// ExcludedBy names the exclusion rule that dropped the profile, if any.
+	ExcludedBy string
+	AddedAt    time.Time
+}
+
+// ProfileDetails is what was scraped from a member's profile page, used to
+// personalize notes and messages.
+type ProfileDetails struct {
+	ID         int64
+	ProfileURL string
+	FirstName  string
+	LastName   string
+	Headline   string
+	Position   string // Current job title
+	Company    string // Current company
+	Location   string
+	About      string
+	ScrapedAt  time.Time
+}
+
+// CachedSearchPage is a page of search results kept to avoid scraping the
+// same page again within the cache TTL.
+type CachedSearchPage struct {
+	QueryKey  string // Normalized search URL without the page number
+	Page      int
+	Results   string // JSON-encoded results, owned by the search package
+	HasNext   bool
+	FetchedAt time.Time
+}
+
+// RunBudget records how much of its scrape budget one run of a campaign used.
+type RunBudget struct {
+	ID                 int64
+	Campaign           string
+	StartedAt          time.Time
+	ResultPages        int
+	ResultPagesLimit   int // 0 means unlimited
+	CachedPages        int
+	ProfileVisits      int
+	ProfileVisitsLimit int // 0 means unlimited
+}
+
+// LimitEventKind is a kind of restriction the site showed us.
+type LimitEventKind string
+
+const (
+	// LimitEventWeeklyInvitations is LinkedIn's weekly invitation limit dialog.
+	LimitEventWeeklyInvitations LimitEventKind = "weekly_invitation_limit"
+	// LimitEventEmailRequired is the dialog asking for the member's email to connect.
+	LimitEventEmailRequired LimitEventKind = "email_required"
+)
+
+// LimitEvent records a restriction dialog shown while doing outreach.
+type LimitEvent struct {
+	ID         int64
+	Kind       LimitEventKind
+	ProfileURL string
+	Message    string // Text of the dialog
+	OccurredAt time.Time
 }
 
 // Storage provides methods for interacting with the database.
@@ -44,6 +208,11 @@ type Storage struct {
 
 // NewStorage initializes and returns a new Storage instance.
 func NewStorage(dbPath string) (*Storage, error) {
+	// SQLite creates the file but not its directory (e.g. accounts/<name>/).
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -78,6 +247,108 @@ func (s *Storage) InitDB() error {
 		UNIQUE(profile_url, message, sent_at) ON CONFLICT IGNORE
 	);`
 
+	createSavedSearchesTableSQL := `
+	CREATE TABLE IF NOT EXISTS saved_searches (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		search_url TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);`
+
+	createSavedSearchProfilesTableSQL := `
+	CREATE TABLE IF NOT EXISTS saved_search_profiles (
+		saved_search_id INTEGER NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+		profile_url TEXT NOT NULL,
+		first_seen_at DATETIME NOT NULL,
+		UNIQUE(saved_search_id, profile_url) ON CONFLICT IGNORE
+	);`
+
+	createCampaignProfilesTableSQL := `
+	CREATE TABLE IF NOT EXISTS campaign_profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		campaign TEXT NOT NULL,
+		profile_url TEXT NOT NULL,
+		name TEXT,
+		company TEXT,
+		variables TEXT,
+		source TEXT NOT NULL,
+		status TEXT NOT NULL,
+		added_at DATETIME NOT NULL,
+		UNIQUE(campaign, profile_url)
+	);`
+
+	createProfileDetailsTableSQL := `
+	CREATE TABLE IF NOT EXISTS profile_details (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT NOT NULL UNIQUE,
+		first_name TEXT,
+		last_name TEXT,
+		headline TEXT,
+		position TEXT,
+		company TEXT,
+		location TEXT,
+		about TEXT,
+		scraped_at DATETIME NOT NULL
+	);`
+
+	createSearchCacheTableSQL := `
+	CREATE TABLE IF NOT EXISTS search_cache (
+		query_key TEXT NOT NULL,
+		page INTEGER NOT NULL,
+		results TEXT NOT NULL,
+		has_next INTEGER NOT NULL,
+		fetched_at DATETIME NOT NULL,
+		PRIMARY KEY(query_key, page)
+	);`
+
+	createRunBudgetsTableSQL := `
+	CREATE TABLE IF NOT EXISTS run_budgets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		campaign TEXT NOT NULL,
+		started_at DATETIME NOT NULL,
+		result_pages INTEGER NOT NULL,
+		result_pages_limit INTEGER NOT NULL,
+		cached_pages INTEGER NOT NULL,
+		profile_visits INTEGER NOT NULL,
+		profile_visits_limit INTEGER NOT NULL
+	);`
+
+	createFailedAttemptsTableSQL := `
+	CREATE TABLE IF NOT EXISTS failed_attempts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action TEXT NOT NULL,
+		profile_url TEXT,
+		reason TEXT NOT NULL,
+		screenshot_path TEXT,
+		attempted_at DATETIME NOT NULL
+	);`
+
+	createActionsTableSQL := `
+	CREATE TABLE IF NOT EXISTS actions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		campaign TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		profile_url TEXT NOT NULL,
+		payload TEXT,
+		scheduled_at DATETIME NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		state TEXT NOT NULL,
+		last_error TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS actions_due ON actions (state, scheduled_at);
+	CREATE UNIQUE INDEX IF NOT EXISTS actions_open ON actions (kind, profile_url) WHERE state IN ('pending', 'running');`
+
+	createLimitEventsTableSQL := `
+	CREATE TABLE IF NOT EXISTS limit_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		profile_url TEXT,
+		message TEXT,
+		occurred_at DATETIME NOT NULL
+	);`
+
 	_, err := s.db.Exec(createRequestsTableSQL)
 	if err != nil {
 		return fmt.Errorf("failed to create sent_requests table: %w", err)
@@ -88,10 +359,133 @@ func (s *Storage) InitDB() error {
 		return fmt.Errorf("failed to create message_records table: %w", err)
 	}
 
+	_, err = s.db.Exec(createSavedSearchesTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create saved_searches table: %w", err)
+	}
+
+	if err := s.addColumnIfMissing("saved_searches", "last_run_at", "DATETIME"); err != nil {
+		return err
+	}
+
+	if err := s.addColumnIfMissing("sent_requests", "template_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("sent_requests", "attempts", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("message_records", "attempts", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(createSavedSearchProfilesTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create saved_search_profiles table: %w", err)
+	}
+
+	_, err = s.db.Exec(createCampaignProfilesTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create campaign_profiles table: %w", err)
+	}
+
+	for _, column := range [][2]string{
+		{"headline", "TEXT"},
+		{"location", "TEXT"},
+		{"connection_degree", "INTEGER NOT NULL DEFAULT 0"},
+		{"excluded_by", "TEXT"},
+		{"mutual_connections", "INTEGER NOT NULL DEFAULT 0"},
+		{"score", "REAL NOT NULL DEFAULT 0"},
+	} {
+		if err := s.addColumnIfMissing("campaign_profiles", column[0], column[1]); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Exec(createProfileDetailsTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create profile_details table: %w", err)
+	}
+
+	_, err = s.db.Exec(createSearchCacheTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create search_cache table: %w", err)
+	}
+
+	_, err = s.db.Exec(createRunBudgetsTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create run_budgets table: %w", err)
+	}
+
+	_, err = s.db.Exec(createFailedAttemptsTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create failed_attempts table: %w", err)
+	}
+
+	if err := s.addColumnIfMissing("failed_attempts", "html_path", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("failed_attempts", "attempt", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(createActionsTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create actions table: %w", err)
+	}
+
+	_, err = s.db.Exec(createLimitEventsTableSQL)
+	if err != nil {
+		return fmt.Errorf("failed to create limit_events table: %w", err)
+	}
+
 	log.Println("Database tables initialized successfully.")
 	return nil
 }
 
+// addColumnIfMissing adds a column to a table created by an older version of
+// the tool, since CREATE TABLE IF NOT EXISTS leaves existing tables unchanged.
+func (s *Storage) addColumnIfMissing(table, column, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return fmt.Errorf("failed to inspect %s table: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
+	return nil
+}
+
+// atLeastOne returns n, or 1 for records saved without an attempt count.
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
 // Close closes the database connection.
 func (s *Storage) Close() error {
 	return s.db.Close()
@@ -99,8 +493,8 @@ func (s *Storage) Close() error {
 
 // SaveSentRequest saves a new sent connection request to the database.
 func (s *Storage) SaveSentRequest(req *SentRequest) error {
-	query := `INSERT INTO sent_requests (profile_url, note, sent_at, status) VALUES (?, ?, ?, ?)`
-	_, err := s.db.Exec(query, req.ProfileURL, req.Note, req.SentAt, req.Status)
+	query := `INSERT INTO sent_requests (profile_url, note, template_id, sent_at, status, attempts) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := s.db.Exec(query, req.ProfileURL, req.Note, req.TemplateID, req.SentAt, req.Status, atLeastOne(req.Attempts))
 	if err != nil {
 		return fmt.Errorf("failed to save sent request: %w", err)
 	}
@@ -109,17 +503,20 @@ func (s *Storage) SaveSentRequest(req *SentRequest) error {
 
 // GetSentRequestByProfileURL retrieves a sent request by its profile URL.
 func (s *Storage) GetSentRequestByProfileURL(profileURL string) (*SentRequest, error) {
-	query := `SELECT id, profile_url, note, sent_at, status FROM sent_requests WHERE profile_url = ?`
+	query := `SELECT id, profile_url, note, template_id, sent_at, status, attempts FROM sent_requests WHERE profile_url = ?`
 	row := s.db.QueryRow(query, profileURL)
 
 	req := &SentRequest{}
-	err := row.Scan(&req.ID, &req.ProfileURL, &req.Note, &req.SentAt, &req.Status)
+	var note, templateID sql.NullString
+	err := row.Scan(&req.ID, &req.ProfileURL, &note, &templateID, &req.SentAt, &req.Status, &req.Attempts)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
 		}
 		return nil, fmt.Errorf("failed to get sent request: %w", err)
 	}
+	req.Note = note.String
+	req.TemplateID = templateID.String
 	return req, nil
 }
 
@@ -138,19 +535,33 @@ func (s *Storage) GetCountOfSentRequestsToday() (int, error) {
 	today := time.Now().Format("2006-01-02") + " 00:00:00"
 	tomorrow := time.Now().Add(24 * time.Hour).Format("2006-01-02") + " 00:00:00"
 
-	query := `SELECT COUNT(*) FROM sent_requests WHERE sent_at >= ? AND sent_at < ?`
+	query := `SELECT COUNT(*) FROM sent_requests WHERE sent_at >= ? AND sent_at < ? AND status NOT IN (?, ?)`
 	var count int
-	err := s.db.QueryRow(query, today, tomorrow).Scan(&count)
+	err := s.db.QueryRow(query, today, tomorrow, StatusAlreadyConnected, StatusAlreadyPending).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get count of sent requests today: %w", err)
 	}
 	return count, nil
 }
 
+// GetCountOfMessagesToday returns the number of follow-up messages sent today.
+func (s *Storage) GetCountOfMessagesToday() (int, error) {
+	today := time.Now().Format("2006-01-02") + " 00:00:00"
+	tomorrow := time.Now().Add(24 * time.Hour).Format("2006-01-02") + " 00:00:00"
+
+	query := `SELECT COUNT(*) FROM message_records WHERE sent_at >= ? AND sent_at < ?`
+	var count int
+	err := s.db.QueryRow(query, today, tomorrow).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get count of messages sent today: %w", err)
+	}
+	return count, nil
+}
+
 // SaveMessageRecord saves a new message record to the database.
 func (s *Storage) SaveMessageRecord(msg *MessageRecord) error {
-	query := `INSERT INTO message_records (profile_url, message, sent_at, template_used) VALUES (?, ?, ?, ?)`
-	_, err := s.db.Exec(query, msg.ProfileURL, msg.Message, msg.SentAt, msg.TemplateUsed)
+	query := `INSERT INTO message_records (profile_url, message, sent_at, template_used, attempts) VALUES (?, ?, ?, ?, ?)`
+	_, err := s.db.Exec(query, msg.ProfileURL, msg.Message, msg.SentAt, msg.TemplateUsed, atLeastOne(msg.Attempts))
 	if err != nil {
 		return fmt.Errorf("failed to save message record: %w", err)
 	}
@@ -159,11 +570,11 @@ func (s *Storage) SaveMessageRecord(msg *MessageRecord) error {
 
 // GetMessageRecord retrieves a message record for a profile.
 func (s *Storage) GetMessageRecord(profileURL string) (*MessageRecord, error) {
-	query := `SELECT id, profile_url, message, sent_at, template_used FROM message_records WHERE profile_url = ? ORDER BY sent_at DESC LIMIT 1`
+	query := `SELECT id, profile_url, message, sent_at, template_used, attempts FROM message_records WHERE profile_url = ? ORDER BY sent_at DESC LIMIT 1`
 	row := s.db.QueryRow(query, profileURL)
 
 	msg := &MessageRecord{}
-	err := row.Scan(&msg.ID, &msg.ProfileURL, &msg.Message, &msg.SentAt, &msg.TemplateUsed)
+	err := row.Scan(&msg.ID, &msg.ProfileURL, &msg.Message, &msg.SentAt, &msg.TemplateUsed, &msg.Attempts)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
@@ -217,3 +628,610 @@ func (s *Storage) GetProfilesWithAcceptedRequestsWithoutMessage() ([]string, err
 	}
 	return profileURLs, nil
 }
+
+// SaveSavedSearch stores a saved search, replacing the URL of an existing search with the same name.
+func (s *Storage) SaveSavedSearch(search *SavedSearch) error {
+	query := `
+	INSERT INTO saved_searches (name, search_url, created_at) VALUES (?, ?, ?)
+	ON CONFLICT(name) DO UPDATE SET search_url = excluded.search_url`
+	_, err := s.db.Exec(query, search.Name, search.SearchURL, search.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save saved search: %w", err)
+	}
+	return nil
+}
+
+// GetSavedSearch retrieves a saved search by name.
+func (s *Storage) GetSavedSearch(name string) (*SavedSearch, error) {
+	query := `SELECT id, name, search_url, created_at, last_run_at FROM saved_searches WHERE name = ?`
+	row := s.db.QueryRow(query, name)
+
+	search := &SavedSearch{}
+	var lastRunAt sql.NullTime
+	err := row.Scan(&search.ID, &search.Name, &search.SearchURL, &search.CreatedAt, &lastRunAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	search.LastRunAt = lastRunAt.Time
+	return search, nil
+}
+
+// ListSavedSearches retrieves all saved searches ordered by name.
+func (s *Storage) ListSavedSearches() ([]SavedSearch, error) {
+	query := `SELECT id, name, search_url, created_at, last_run_at FROM saved_searches ORDER BY name`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []SavedSearch
+	for rows.Next() {
+		var search SavedSearch
+		var lastRunAt sql.NullTime
+		if err := rows.Scan(&search.ID, &search.Name, &search.SearchURL, &search.CreatedAt, &lastRunAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		search.LastRunAt = lastRunAt.Time
+		searches = append(searches, search)
+	}
+	return searches, nil
+}
+
+// DeleteSavedSearch removes a saved search, and the profiles it has seen, by name.
+// It reports whether a search was deleted.
+func (s *Storage) DeleteSavedSearch(name string) (bool, error) {
+	_, err := s.db.Exec(`DELETE FROM saved_search_profiles WHERE saved_search_id IN (SELECT id FROM saved_searches WHERE name = ?)`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search profiles: %w", err)
+	}
+	res, err := s.db.Exec(`DELETE FROM saved_searches WHERE name = ?`, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetSavedSearchProfileURLs retrieves every profile URL previous runs of a saved search have found.
+func (s *Storage) GetSavedSearchProfileURLs(savedSearchID int64) ([]string, error) {
+	query := `SELECT profile_url FROM saved_search_profiles WHERE saved_search_id = ?`
+	rows, err := s.db.Query(query, savedSearchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profileURLs []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, fmt.Errorf("failed to scan profile URL: %w", err)
+		}
+		profileURLs = append(profileURLs, url)
+	}
+	return profileURLs, nil
+}
+
+// RecordSavedSearchRun stores the profiles a run of a saved search found and sets its last-run time.
+func (s *Storage) RecordSavedSearchRun(savedSearchID int64, profileURLs []string, ranAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to record saved search run: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, profileURL := range profileURLs {
+		_, err := tx.Exec(`INSERT INTO saved_search_profiles (saved_search_id, profile_url, first_seen_at) VALUES (?, ?, ?)`, savedSearchID, profileURL, ranAt)
+		if err != nil {
+			return fmt.Errorf("failed to record saved search profile: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE saved_searches SET last_run_at = ? WHERE id = ?`, ranAt, savedSearchID); err != nil {
+		return fmt.Errorf("failed to update saved search last run: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record saved search run: %w", err)
+	}
+	return nil
+}
+
+// QueueCampaignProfile adds a profile to a campaign's queue. It reports false,
+// leaving the existing entry untouched, if the campaign already has the profile.
+func (s *Storage) QueueCampaignProfile(profile *CampaignProfile) (bool, error) {
+	variables, err := json.Marshal(profile.Variables)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode profile variables: %w", err)
+	}
+	status := profile.Status
+	if status == "" {
+		status = CampaignProfileQueued
+	}
+	query := `
+	INSERT INTO campaign_profiles (campaign, profile_url, name, company, headline, location, connection_degree, mutual_connections, score, variables, source, status, added_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(campaign, profile_url) DO NOTHING`
+	res, err := s.db.Exec(query, profile.Campaign, profile.ProfileURL, profile.Name, profile.Company, profile.Headline, profile.Location, profile.ConnectionDegree,
+		profile.MutualConnections, profile.Score, string(variables), profile.Source, status, profile.AddedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to queue campaign profile: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to queue campaign profile: %w", err)
+	}
+	return n > 0, nil
+}
+
+// campaignProfileColumns are the columns scanned by scanCampaignProfile.
+const campaignProfileColumns = `id, campaign, profile_url, name, company, headline, location, connection_degree, mutual_connections, score, variables, source, status, added_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCampaignProfile scans a row selected with campaignProfileColumns.
+func scanCampaignProfile(row rowScanner) (CampaignProfile, error) {
+	var profile CampaignProfile
+	var name, company, headline, location, variables sql.NullString
+	if err := row.Scan(&profile.ID, &profile.Campaign, &profile.ProfileURL, &name, &company, &headline, &location, &profile.ConnectionDegree,
+		&profile.MutualConnections, &profile.Score, &variables, &profile.Source, &profile.Status, &profile.AddedAt); err != nil {
+		return profile, err
+	}
+	profile.Name = name.String
+	profile.Company = company.String
+	profile.Headline = headline.String
+	profile.Location = location.String
+	if variables.String != "" {
+		if err := json.Unmarshal([]byte(variables.String), &profile.Variables); err != nil {
+			return profile, fmt.Errorf("failed to decode variables of %s: %w", profile.ProfileURL, err)
+		}
+	}
+	return profile, nil
+}
+
+// GetQueuedCampaignProfiles retrieves the profiles of a campaign still waiting
+// for outreach, highest score first and otherwise oldest first.
+func (s *Storage) GetQueuedCampaignProfiles(campaign string) ([]CampaignProfile, error) {
+	query := `SELECT ` + campaignProfileColumns + `
+	FROM campaign_profiles WHERE campaign = ? AND status = ? ORDER BY score DESC, added_at, id`
+	rows, err := s.db.Query(query, campaign, CampaignProfileQueued)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queued campaign profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []CampaignProfile
+	for rows.Next() {
+		profile, err := scanCampaignProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan campaign profile: %w", err)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// GetCampaignProfile retrieves a profile of a campaign's queue, whatever its
+// status, or nil if the profile is not part of the campaign.
+func (s *Storage) GetCampaignProfile(campaign, profileURL string) (*CampaignProfile, error) {
+	query := `SELECT ` + campaignProfileColumns + ` FROM campaign_profiles WHERE campaign = ? AND profile_url = ?`
+	profile, err := scanCampaignProfile(s.db.QueryRow(query, campaign, profileURL))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not in the campaign
+		}
+		return nil, fmt.Errorf("failed to get campaign profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// UpdateCampaignProfileStatus sets the status of a profile in a campaign's queue.
+func (s *Storage) UpdateCampaignProfileStatus(campaign, profileURL string, status CampaignProfileStatus) error {
+	query := `UPDATE campaign_profiles SET status = ? WHERE campaign = ? AND profile_url = ?`
+	_, err := s.db.Exec(query, status, campaign, profileURL)
+	if err != nil {
+		return fmt.Errorf("failed to update campaign profile status: %w", err)
+	}
+	return nil
+}
+
+// UpdateCampaignProfileScore stores the lead score of a profile in a campaign's queue.
+func (s *Storage) UpdateCampaignProfileScore(campaign, profileURL string, score float64) error {
+	query := `UPDATE campaign_profiles SET score = ? WHERE campaign = ? AND profile_url = ?`
+	_, err := s.db.Exec(query, score, campaign, profileURL)
+	if err != nil {
+		return fmt.Errorf("failed to update campaign profile score: %w", err)
+	}
+	return nil
+}
+
+// CurrentCompany returns the profile's company, falling back to the part of
+// the headline after " at " or " @ " ("Engineer at Acme") when it is
+// unknown. The fallback is lower-cased.
+func (p CampaignProfile) CurrentCompany() string {
+	if p.Company != "" {
+		return p.Company
+	}
+	headline := strings.ToLower(p.Headline)
+	for _, separator := range []string{" at ", " @ "} {
+		if i := strings.LastIndex(headline, separator); i >= 0 {
+			return headline[i+len(separator):]
+		}
+	}
+	return ""
+}
+
+// ExcludeCampaignProfile marks a queued profile as excluded, recording the rule that dropped it.
+func (s *Storage) ExcludeCampaignProfile(campaign, profileURL, rule string) error {
+	query := `UPDATE campaign_profiles SET status = ?, excluded_by = ? WHERE campaign = ? AND profile_url = ?`
+	_, err := s.db.Exec(query, CampaignProfileExcluded, rule, campaign, profileURL)
+	if err != nil {
+		return fmt.Errorf("failed to exclude campaign profile: %w", err)
+	}
+	return nil
+}
+
+// SaveProfileDetails stores scraped profile details, replacing any earlier scrape of the same profile.
+func (s *Storage) SaveProfileDetails(details *ProfileDetails) error {
+	query := `
+	INSERT INTO profile_details (profile_url, first_name, last_name, headline, position, company, location, about, scraped_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(profile_url) DO UPDATE SET
+		first_name = excluded.first_name, last_name = excluded.last_name, headline = excluded.headline,
+		position = excluded.position, company = excluded.company, location = excluded.location,
+		about = excluded.about, scraped_at = excluded.scraped_at`
+	_, err := s.db.Exec(query, details.ProfileURL, details.FirstName, details.LastName, details.Headline,
+		details.Position, details.Company, details.Location, details.About, details.ScrapedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save profile details: %w", err)
+	}
+	return nil
+}
+
+// GetProfileDetails retrieves the scraped details of a profile.
+func (s *Storage) GetProfileDetails(profileURL string) (*ProfileDetails, error) {
+	query := `
+	SELECT id, profile_url, first_name, last_name, headline, position, company, location, about, scraped_at
+	FROM profile_details WHERE profile_url = ?`
+	row := s.db.QueryRow(query, profileURL)
+
+	details := &ProfileDetails{}
+	var firstName, lastName, headline, position, company, location, about sql.NullString
+	err := row.Scan(&details.ID, &details.ProfileURL, &firstName, &lastName, &headline, &position, &company, &location, &about, &details.ScrapedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get profile details: %w", err)
+	}
+	details.FirstName = firstName.String
+	details.LastName = lastName.String
+	details.Headline = headline.String
+	details.Position = position.String
+	details.Company = company.String
+	details.Location = location.String
+	details.About = about.String
+	return details, nil
+}
+
+// GetCachedSearchPage retrieves a cached result page fetched at or after notBefore.
+func (s *Storage) GetCachedSearchPage(queryKey string, page int, notBefore time.Time) (*CachedSearchPage, error) {
+	query := `SELECT query_key, page, results, has_next, fetched_at FROM search_cache WHERE query_key = ? AND page = ? AND fetched_at >= ?`
+	row := s.db.QueryRow(query, queryKey, page, notBefore)
+
+	cached := &CachedSearchPage{}
+	err := row.Scan(&cached.QueryKey, &cached.Page, &cached.Results, &cached.HasNext, &cached.FetchedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not cached or expired
+		}
+		return nil, fmt.Errorf("failed to get cached search page: %w", err)
+	}
+	return cached, nil
+}
+
+// SaveCachedSearchPage stores a result page, replacing an older copy.
+func (s *Storage) SaveCachedSearchPage(cached *CachedSearchPage) error {
+	query := `
+	INSERT INTO search_cache (query_key, page, results, has_next, fetched_at) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(query_key, page) DO UPDATE SET results = excluded.results, has_next = excluded.has_next, fetched_at = excluded.fetched_at`
+	_, err := s.db.Exec(query, cached.QueryKey, cached.Page, cached.Results, cached.HasNext, cached.FetchedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save cached search page: %w", err)
+	}
+	return nil
+}
+
+// SaveRunBudget records the budget consumption of a run.
+func (s *Storage) SaveRunBudget(run *RunBudget) error {
+	query := `
+	INSERT INTO run_budgets (campaign, started_at, result_pages, result_pages_limit, cached_pages, profile_visits, profile_visits_limit)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.db.Exec(query, run.Campaign, run.StartedAt, run.ResultPages, run.ResultPagesLimit, run.CachedPages, run.ProfileVisits, run.ProfileVisitsLimit)
+	if err != nil {
+		return fmt.Errorf("failed to save run budget: %w", err)
+	}
+	return nil
+}
+
+// ListRunBudgets retrieves the budget consumption of runs started at or after since, by campaign and then oldest first.
+func (s *Storage) ListRunBudgets(since time.Time) ([]RunBudget, error) {
+	query := `
+	SELECT id, campaign, started_at, result_pages, result_pages_limit, cached_pages, profile_visits, profile_visits_limit
+	FROM run_budgets WHERE started_at >= ? ORDER BY campaign, started_at`
+	rows, err := s.db.Query(query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []RunBudget
+	for rows.Next() {
+		var run RunBudget
+		if err := rows.Scan(&run.ID, &run.Campaign, &run.StartedAt, &run.ResultPages, &run.ResultPagesLimit, &run.CachedPages, &run.ProfileVisits, &run.ProfileVisitsLimit); err != nil {
+			return nil, fmt.Errorf("failed to scan run budget: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// SaveFailedAttempt records an action that failed or could not be confirmed.
+func (s *Storage) SaveFailedAttempt(attempt *FailedAttempt) error {
+	query := `INSERT INTO failed_attempts (action, profile_url, reason, screenshot_path, html_path, attempt, attempted_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.db.Exec(query, attempt.Action, attempt.ProfileURL, attempt.Reason, attempt.ScreenshotPath, attempt.HTMLPath, atLeastOne(attempt.Attempt), attempt.AttemptedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save failed attempt: %w", err)
+	}
+	attempt.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// SaveLimitEvent records a restriction dialog. Times are stored in UTC so
+// they compare correctly.
+func (s *Storage) SaveLimitEvent(event *LimitEvent) error {
+	query := `INSERT INTO limit_events (kind, profile_url, message, occurred_at) VALUES (?, ?, ?, ?)`
+	result, err := s.db.Exec(query, event.Kind, event.ProfileURL, event.Message, event.OccurredAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save limit event: %w", err)
+	}
+	event.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// GetLatestLimitEvent retrieves the most recent event of a kind that occurred
+// at or after since, or nil if there is none.
+func (s *Storage) GetLatestLimitEvent(kind LimitEventKind, since time.Time) (*LimitEvent, error) {
+	query := `
+	SELECT id, kind, profile_url, message, occurred_at FROM limit_events
+	WHERE kind = ? AND occurred_at >= ? ORDER BY occurred_at DESC, id DESC LIMIT 1`
+	row := s.db.QueryRow(query, kind, since.UTC())
+
+	event := &LimitEvent{}
+	var profileURL, message sql.NullString
+	err := row.Scan(&event.ID, &event.Kind, &profileURL, &message, &event.OccurredAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No recent event
+		}
+		return nil, fmt.Errorf("failed to get latest limit event: %w", err)
+	}
+	event.ProfileURL = profileURL.String
+	event.Message = message.String
+	return event, nil
+}
+
+// actionColumns are the columns scanned by scanAction.
+const actionColumns = `id, campaign, kind, profile_url, payload, scheduled_at, attempts, state, last_error, created_at, updated_at`
+
+// scanAction scans a row selected with actionColumns.
+func scanAction(row rowScanner) (QueuedAction, error) {
+	var action QueuedAction
+	var payload, lastError sql.NullString
+	if err := row.Scan(&action.ID, &action.Campaign, &action.Kind, &action.ProfileURL, &payload, &action.ScheduledAt,
+		&action.Attempts, &action.State, &lastError, &action.CreatedAt, &action.UpdatedAt); err != nil {
+		return action, err
+	}
+	action.LastError = lastError.String
+	if payload.String != "" {
+		if err := json.Unmarshal([]byte(payload.String), &action.Payload); err != nil {
+			return action, fmt.Errorf("failed to decode payload of action %d: %w", action.ID, err)
+		}
+	}
+	return action, nil
+}
+
+// EnqueueAction plans an action. Times are stored in UTC so they compare
+// correctly. It reports false, without error, if the same kind of action is
+// already pending or running for the profile.
+func (s *Storage) EnqueueAction(action *QueuedAction) (bool, error) {
+	payload, err := json.Marshal(action.Payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode action payload: %w", err)
+	}
+	now := time.Now().UTC()
+	scheduledAt := action.ScheduledAt
+	if scheduledAt.IsZero() {
+		scheduledAt = now
+	}
+	query := `
+	INSERT OR IGNORE INTO actions (campaign, kind, profile_url, payload, scheduled_at, attempts, state, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?)`
+	res, err := s.db.Exec(query, action.Campaign, action.Kind, action.ProfileURL, string(payload), scheduledAt.UTC(), ActionPending, now, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue action: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to enqueue action: %w", err)
+	}
+	if n > 0 {
+		action.ID, _ = res.LastInsertId()
+		action.ScheduledAt, action.State, action.CreatedAt, action.UpdatedAt = scheduledAt, ActionPending, now, now
+	}
+	return n > 0, nil
+}
+
+// ClaimDueAction marks the earliest pending action scheduled at or before
+// now as running, counts the attempt and returns it. Actions of the kinds in
+// skip are left alone. It returns nil if nothing is due.
+func (s *Storage) ClaimDueAction(now time.Time, skip []ActionKind) (*QueuedAction, error) {
+	query := `SELECT ` + actionColumns + ` FROM actions WHERE state = ? AND scheduled_at <= ?`
+	args := []interface{}{ActionPending, now.UTC()}
+	if len(skip) > 0 {
+		query += ` AND kind NOT IN (?` + strings.Repeat(`, ?`, len(skip)-1) + `)`
+		for _, kind := range skip {
+			args = append(args, kind)
+		}
+	}
+	query += ` ORDER BY scheduled_at, id LIMIT 1`
+
+	action, err := scanAction(s.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Nothing due
+		}
+		return nil, fmt.Errorf("failed to get due action: %w", err)
+	}
+
+	// Only one worker runs at a time, but make sure nobody claimed it meanwhile.
+	res, err := s.db.Exec(`UPDATE actions SET state = ?, attempts = attempts + 1, updated_at = ? WHERE id = ? AND state = ?`,
+		ActionRunning, time.Now().UTC(), action.ID, ActionPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim action %d: %w", action.ID, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return nil, fmt.Errorf("action %d was claimed by another worker", action.ID)
+	}
+	action.State = ActionRunning
+	action.Attempts++
+	return &action, nil
+}
+
+// CountDueActions counts the pending actions scheduled at or before now, per
+// kind.
+func (s *Storage) CountDueActions(now time.Time) (map[ActionKind]int, error) {
+	rows, err := s.db.Query(`SELECT kind, COUNT(*) FROM actions WHERE state = ? AND scheduled_at <= ? GROUP BY kind`, ActionPending, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count due actions: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[ActionKind]int)
+	for rows.Next() {
+		var kind ActionKind
+		var count int
+		if err := rows.Scan(&kind, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan due action count: %w", err)
+		}
+		counts[kind] = count
+	}
+	return counts, nil
+}
+
+// FinishAction records the final state of an action and the error, if any,
+// that ended it.
+func (s *Storage) FinishAction(id int64, state ActionState, lastError string) error {
+	query := `UPDATE actions SET state = ?, last_error = ?, updated_at = ? WHERE id = ?`
+	_, err := s.db.Exec(query, state, lastError, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to finish action %d: %w", id, err)
+	}
+	return nil
+}
+
+// RescheduleAction puts an action back into the queue for the given time,
+// noting why it was postponed.
+func (s *Storage) RescheduleAction(id int64, at time.Time, lastError string) error {
+	query := `UPDATE actions SET state = ?, scheduled_at = ?, last_error = ?, updated_at = ? WHERE id = ?`
+	_, err := s.db.Exec(query, ActionPending, at.UTC(), lastError, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule action %d: %w", id, err)
+	}
+	return nil
+}
+
+// ReleaseRunningActions returns actions left running by a run that was
+// interrupted to the queue, and reports how many there were.
+func (s *Storage) ReleaseRunningActions() (int, error) {
+	res, err := s.db.Exec(`UPDATE actions SET state = ?, updated_at = ? WHERE state = ?`, ActionPending, time.Now().UTC(), ActionRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release running actions: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to release running actions: %w", err)
+	}
+	return int(n), nil
+}
+
+// ListActions retrieves the actions in a state, or all actions if state is
+// empty, in the order they are scheduled.
+func (s *Storage) ListActions(state ActionState) ([]QueuedAction, error) {
+	query := `SELECT ` + actionColumns + ` FROM actions`
+	var args []interface{}
+	if state != "" {
+		query += ` WHERE state = ?`
+		args = append(args, state)
+	}
+	query += ` ORDER BY scheduled_at, id`
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []QueuedAction
+	for rows.Next() {
+		action, err := scanAction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// CancelAction cancels a pending action. It reports false if there is no
+// pending action with that ID.
+func (s *Storage) CancelAction(id int64) (bool, error) {
+	res, err := s.db.Exec(`UPDATE actions SET state = ?, updated_at = ? WHERE id = ? AND state = ?`, ActionCancelled, time.Now().UTC(), id, ActionPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel action %d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel action %d: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+// HasFailedAction reports whether an earlier action of kind for a profile
+// failed, i.e. ran out of attempts. Automatic planning leaves such profiles
+// alone so a failure is not retried on every run.
+func (s *Storage) HasFailedAction(kind ActionKind, profileURL string) (bool, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM actions WHERE kind = ? AND profile_url = ? AND state = ?`, kind, profileURL, ActionFailed).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check earlier %s actions for %s: %w", kind, profileURL, err)
+	}
+	return count > 0, nil
+}
+
+// CountPendingActions returns the number of pending actions of a kind.
+func (s *Storage) CountPendingActions(kind ActionKind) (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM actions WHERE kind = ? AND state = ?`, kind, ActionPending).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending %s actions: %w", kind, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,83 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrUnresolved is returned when a rendered text still contains placeholders.
+var ErrUnresolved = errors.New("unresolved template placeholders")
+
+// placeholderPattern matches a {{Name}} template placeholder.
+var placeholderPattern = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+// Render substitutes variables into a template.
+// Variable names are matched case-insensitively because variables loaded
+// through the config are lower-cased; unknown placeholders are left as-is.
+func Render(template string, variables map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		for key, value := range variables {
+			if strings.EqualFold(key, name) {
+				return value
+			}
+		}
+		return placeholder
+	})
+}
+
+// RenderStrict renders a template and fails, naming every missing variable,
+// if any placeholder is left unresolved.
+func RenderStrict(template string, variables map[string]string) (string, error) {
+	text := Render(template, variables)
+	if missing := Placeholders(text); len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnresolved, strings.Join(missing, ", "))
+	}
+	return text, nil
+}
+
+// Placeholders returns the names of the placeholders in text, in order of
+// first appearance.
+func Placeholders(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// ID identifies a version of a named template, e.g. "connection_note@1a2b3c4d".
+// Editing the template text changes its ID, so stored records show exactly
+// which wording was used.
+func ID(name, template string) string {
+	sum := sha256.Sum256([]byte(template))
+	return name + "@" + hex.EncodeToString(sum[:4])
+}
+
+// Merge combines variable maps; later maps win. Keys are compared
+// case-insensitively, so a later "name" replaces an earlier "Name".
+func Merge(layers ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, layer := range layers {
+		for key, value := range layer {
+			for existing := range merged {
+				if strings.EqualFold(existing, key) {
+					delete(merged, existing)
+				}
+			}
+			merged[key] = value
+		}
+	}
+	return merged
+}
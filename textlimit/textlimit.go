@@ -0,0 +1,161 @@
+package textlimit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Overflow says what to do with text over its length limit.
+type Overflow string
+
+const (
+	Reject   Overflow = "reject"   // Fail, so nothing is sent
+	Truncate Overflow = "truncate" // Cut at a word boundary and add an ellipsis
+)
+
+// ErrTooLong is returned when text over its limit is rejected.
+var ErrTooLong = errors.New("text too long")
+
+// ellipsis marks truncated text.
+const ellipsis = "…"
+
+// Length counts characters the way LinkedIn's text fields do: in UTF-16 code
+// units, like a JavaScript string. Most characters, including accented and
+// non-Latin letters, count once; emoji outside the Basic Multilingual Plane
+// count twice.
+func Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += runeLength(r)
+	}
+	return length
+}
+
+// runeLength is the number of UTF-16 code units r takes.
+func runeLength(r rune) int {
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+	return 1 // Invalid UTF-8 is replaced by U+FFFD, one unit
+}
+
+// Enforce checks text against a limit of maxLength characters. Text within
+// the limit is returned unchanged; longer text is either rejected with an
+// error wrapping ErrTooLong or truncated, depending on overflow.
+func Enforce(text string, maxLength int, overflow Overflow) (string, error) {
+	length := Length(text)
+	if length <= maxLength {
+		return text, nil
+	}
+	if overflow == Truncate {
+		return Shorten(text, maxLength), nil
+	}
+	return "", fmt.Errorf("%w: %d characters, over the limit of %d", ErrTooLong, length, maxLength)
+}
+
+// Shorten cuts text to at most maxLength characters. It cuts at the last word
+// boundary within the limit and appends an ellipsis; a single word longer than
+// the limit is cut between characters. It never splits a character, a surrogate
+// pair or an emoji sequence.
+func Shorten(text string, maxLength int) string {
+	if Length(text) <= maxLength {
+		return text
+	}
+	if maxLength <= 0 {
+		return ""
+	}
+	room := maxLength - Length(ellipsis)
+	if room <= 0 {
+		return cut(text, maxLength)
+	}
+
+	prefix := cut(text, room)
+	// Back off to the last whitespace if the cut landed inside a word, so no
+	// word is left half-written. Only a first word longer than the limit is
+	// cut between characters.
+	if next := text[len(prefix):]; inWord(prefix, next) {
+		if i := strings.LastIndexFunc(prefix, unicode.IsSpace); i > 0 {
+			prefix = prefix[:i]
+		}
+	}
+	prefix = strings.TrimRightFunc(prefix, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",;:-–—", r)
+	})
+	return prefix + ellipsis
+}
+
+// cut returns the longest prefix of text that fits in maxLength characters
+// without splitting a character from the marks and joiners that follow it.
+func cut(text string, maxLength int) string {
+	length, end := 0, 0
+	for i, r := range text {
+		length += runeLength(r)
+		if length > maxLength {
+			break
+		}
+		end = i + len(string(r))
+	}
+	prefix := text[:end]
+	// Drop a character whose combining marks, skin tone, variation selector,
+	// zero width joiner sequence or other flag half would be cut off.
+	for end < len(text) && prefix != "" {
+		next := []rune(text[end:])[0]
+		if !isExtending(next) && !strings.HasSuffix(prefix, "\u200d") && !splitsFlag(prefix, next) {
+			break
+		}
+		runes := []rune(prefix)
+		prefix = string(runes[:len(runes)-1])
+		end = len(prefix)
+	}
+	return prefix
+}
+
+// isExtending reports whether r attaches to the character before it.
+func isExtending(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) ||
+		r == '\u200d' || // Zero width joiner
+		(r >= '\ufe00' && r <= '\ufe0f') || // Variation selectors
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // Skin tone modifiers
+		(r >= 0xe0020 && r <= 0xe007f) // Tag characters of subdivision flags
+}
+
+// isRegionalIndicator reports whether r is one of the letters that make up
+// country flags in pairs.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// splitsFlag reports whether cutting between prefix and next would split a
+// pair of regional indicators.
+func splitsFlag(prefix string, next rune) bool {
+	if !isRegionalIndicator(next) {
+		return false
+	}
+	runes := []rune(prefix)
+	count := 0
+	for i := len(runes) - 1; i >= 0 && isRegionalIndicator(runes[i]); i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// inWord reports whether the boundary between before and after falls inside
+// a word, i.e. between two letters or digits. Cutting between emoji or at
+// punctuation leaves no half-written word.
+func inWord(before, after string) bool {
+	if before == "" || after == "" {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(before)
+	first, _ := utf8.DecodeRuneInString(after)
+	return isWordRune(last) && isWordRune(first)
+}
+
+// isWordRune reports whether r is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
+}
@@ -0,0 +1,85 @@
+package textlimit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "Hello", 5},
+		{"precomposed accent", "José", 4},
+		{"combining accent", "Jose\u0301", 5},
+		{"cyrillic", "Дмитрий", 7},
+		{"cjk", "李小龍", 3},
+		{"emoji surrogate pair", "😀", 2},
+		{"skin tone", "👍\U0001F3FD", 4},
+		{"zero width joiner sequence", "👩\u200d💻", 5},
+		{"flag", "🇩🇪", 4},
+		{"invalid utf-8", "\xff", 1},
+	}
+	for _, tt := range tests {
+		if got := Length(tt.text); got != tt.want {
+			t.Errorf("%s: Length(%q) = %d, want %d", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		want      string
+	}{
+		{"within limit", "Hello world", 20, "Hello world"},
+		{"zero limit", "Hello world", 0, ""},
+		{"no room for the ellipsis", "Hello", 1, "H"},
+		{"cut before a space", "Hello world again", 12, "Hello world…"},
+		{"back off to the last word", "Looking forward to connecting", 22, "Looking forward to…"},
+		{"back off to the only earlier word", "Hello wonderful world", 12, "Hello…"},
+		{"first word longer than the limit", "Supercalifragilistic word", 10, "Supercali…"},
+		{"trailing punctuation", "Hi Jane, great talk", 9, "Hi Jane…"},
+		{"single long word", "Supercalifragilistic", 10, "Supercali…"},
+		{"non-latin name", "Дмитрий Иванов", 10, "Дмитрий…"},
+		{"combining accent", "Jose\u0301!", 5, "Jos…"},
+		{"emoji kept whole", "Hi 😀😀", 6, "Hi 😀…"},
+		{"surrogate pair not split", "😀😀", 2, "…"},
+		{"skin tone not split", "Great 👍\U0001F3FD", 9, "Great…"},
+		{"zero width joiner sequence not split", "Dev 👩\u200d💻", 8, "Dev…"},
+		{"flags kept in pairs", "Go 🇩🇪🇫🇷", 9, "Go 🇩🇪…"},
+		{"flag not split", "Go 🇩🇪🇫🇷", 7, "Go…"},
+	}
+	for _, tt := range tests {
+		got := Shorten(tt.text, tt.maxLength)
+		if got != tt.want {
+			t.Errorf("%s: Shorten(%q, %d) = %q, want %q", tt.name, tt.text, tt.maxLength, got, tt.want)
+		}
+		if length := Length(got); length > tt.maxLength {
+			t.Errorf("%s: Shorten(%q, %d) is %d characters long", tt.name, tt.text, tt.maxLength, length)
+		}
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	text := "Looking forward to connecting"
+
+	got, err := Enforce(text, 30, Reject)
+	if err != nil || got != text {
+		t.Errorf("Enforce within limit = %q, %v; want the text unchanged", got, err)
+	}
+
+	if _, err := Enforce(text, 22, Reject); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Enforce with %q = %v, want ErrTooLong", Reject, err)
+	}
+
+	got, err = Enforce(text, 22, Truncate)
+	if err != nil || got != "Looking forward to…" {
+		t.Errorf("Enforce with %q = %q, %v; want %q", Truncate, got, err, "Looking forward to…")
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"linkedin-automation/actions" // Import actions to run the queue
+	"linkedin-automation/budget"
+	"linkedin-automation/config"
+	"linkedin-automation/connection"
+	"linkedin-automation/messaging"
+	"linkedin-automation/profile"
+	"linkedin-automation/schedule" // Import schedule for the working windows
+	"linkedin-automation/stealth"
+	"linkedin-automation/storage"
+	"linkedin-automation/templates"
+)
+
+// templatePayloadKey is the payload key of a queued invite or message that
+// replaces the configured template; every other payload key is a template
+// variable override.
+const templatePayloadKey = "template"
+
+// newWorker creates the worker executing queued actions with the run's
+// components, inside the working windows of the schedule. Waiting stops when
+// ctx is done.
+func newWorker(ctx context.Context, store *storage.Storage, live *config.Live, schedules *schedule.Source, connRequester *connection.ConnectionRequester,
+	messenger *messaging.Messenger, scraper *profile.Scraper) *actions.Worker {
+	worker := actions.NewWorker(store, live)
+	worker.Wait = func() error { return waitUntilActive(ctx, schedules) }
+	worker.Pace = func(kind storage.ActionKind, remaining int) error {
+		if remaining == 0 {
+			return nil
+		}
+		pacing := live.Current().Pacing
+		delay := stealth.RandomDuration(pacing.BetweenConnections.Min, pacing.BetweenConnections.Max) // Human-like delay between requests
+		if kind == storage.ActionMessage {
+			delay = stealth.RandomDuration(pacing.BetweenMessages.Min, pacing.BetweenMessages.Max) // Human-like delay between messages
+		}
+		// Spread what is left over the working window rather than bursting
+		if gap := schedules.Current().Spread(time.Now(), remaining); gap > delay {
+			delay = stealth.RandomDuration(gap*3/4, gap*5/4)
+			log.Printf("Spreading %d remaining actions over the working window, next in %s", remaining, delay.Round(time.Second))
+		}
+		return sleep(ctx, delay)
+	}
+
+	worker.Handle(storage.ActionInvite, func(action *storage.QueuedAction) error {
+		target, err := actionTarget(store, action)
+		if err != nil {
+			return err
+		}
+		if target.Status != storage.CampaignProfileQueued {
+			return fmt.Errorf("profile is %s in campaign %q: %w", target.Status, action.Campaign, actions.ErrSkipped)
+		}
+		current := live.Current()
+		variables, overrides := targetVariables(current, *target)
+		template, overrides := payloadTemplate(action, current.Templates.ConnectionNote, overrides)
+		note := connection.Note{
+			TemplateID: templates.ID("connection_note", template),
+			Template:   template,
+			Variables:  variables,
+			Overrides:  overrides,
+		}
+		err = connRequester.SendConnectionRequest(action.ProfileURL, note)
+		switch {
+		case errors.Is(err, connection.ErrWeeklyLimit) || errors.Is(err, connection.ErrDailyLimit):
+			return fmt.Errorf("%v: %w", err, actions.ErrLimitReached)
+		case errors.Is(err, connection.ErrFollowOnly) || errors.Is(err, connection.ErrProfileUnavailable) ||
+			errors.Is(err, connection.ErrEmailRequired):
+			// Retrying will not help, so take the profile out of the queue.
+			if err := store.ExcludeCampaignProfile(action.Campaign, action.ProfileURL, "profile_state:"+profileStateRule(err)); err != nil {
+				log.Printf("Failed to update campaign status for %s: %v", action.ProfileURL, err)
+			}
+			return err
+		case errors.Is(err, connection.ErrInvalidNote):
+			// The profile stays queued; plan it again with the actions command once the template is fixed.
+			return fmt.Errorf("fix the connection note template: %w", err)
+		case err != nil:
+			return err
+		}
+		if err := store.UpdateCampaignProfileStatus(action.Campaign, action.ProfileURL, storage.CampaignProfileContacted); err != nil {
+			log.Printf("Failed to update campaign status for %s: %v", action.ProfileURL, err)
+		}
+		return nil
+	})
+
+	worker.Handle(storage.ActionMessage, func(action *storage.QueuedAction) error {
+		target, err := actionTarget(store, action)
+		if err != nil {
+			return err
+		}
+		current := live.Current()
+		variables, overrides := targetVariables(current, *target)
+		template, overrides := payloadTemplate(action, current.Templates.FollowUp, overrides)
+		// Details scraped from the profile are more reliable than search results.
+		details, err := store.GetProfileDetails(action.ProfileURL)
+		if err != nil {
+			log.Printf("Failed to load profile details of %s: %v", action.ProfileURL, err)
+		}
+		if details == nil {
+			if details, err = scraper.Scrape(action.ProfileURL); errors.Is(err, budget.ErrExhausted) {
+				return err
+			} else if err != nil {
+				log.Printf("Failed to scrape profile details of %s: %v", action.ProfileURL, err)
+			}
+		}
+		variables = templates.Merge(variables, profile.Variables(details), overrides)
+		err = messenger.SendFollowUpMessage(action.ProfileURL, template, variables)
+		if errors.Is(err, messaging.ErrDailyLimit) {
+			return fmt.Errorf("%v: %w", err, actions.ErrLimitReached)
+		}
+		return err
+	})
+
+	worker.Handle(storage.ActionVisit, func(action *storage.QueuedAction) error {
+		_, err := scraper.Scrape(action.ProfileURL)
+		return err
+	})
+
+	worker.Handle(storage.ActionWithdraw, func(action *storage.QueuedAction) error {
+		err := connRequester.WithdrawInvitation(action.ProfileURL)
+		if errors.Is(err, connection.ErrNotPending) {
+			// Accepted or withdrawn by hand meanwhile
+			return fmt.Errorf("%v: %w", err, actions.ErrSkipped)
+		}
+		return err
+	})
+	return worker
+}
+
+// actionTarget returns what the campaign queue knows about the profile of an
+// action, or just its URL for a profile that is not in the campaign.
+func actionTarget(store *storage.Storage, action *storage.QueuedAction) (*storage.CampaignProfile, error) {
+	target, err := store.GetCampaignProfile(action.Campaign, action.ProfileURL)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		target = &storage.CampaignProfile{Campaign: action.Campaign, ProfileURL: action.ProfileURL, Status: storage.CampaignProfileQueued}
+	}
+	return target, nil
+}
+
+// payloadTemplate applies the payload of a queued action: its template, if
+// any, replaces the configured one and its other keys win over overrides.
+func payloadTemplate(action *storage.QueuedAction, template string, overrides map[string]string) (string, map[string]string) {
+	variables := make(map[string]string)
+	for key, value := range action.Payload {
+		if key == templatePayloadKey {
+			template = value
+		} else {
+			variables[key] = value
+		}
+	}
+	return template, templates.Merge(overrides, variables)
+}